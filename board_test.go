@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskBadge(t *testing.T) {
+	// 2024-05-31 23:30 UTC is already June 1st in Tokyo.
+	due := time.Date(2024, 5, 31, 23, 30, 0, 0, time.UTC).Unix()
+	tokyo := time.FixedZone("JST", 9*60*60)
+	tests := []struct {
+		name string
+		task map[string]interface{}
+		now  time.Time
+		want string
+	}{
+		{"plain", map[string]interface{}{"id": "1", "title": "Write docs"}, time.Now(), "#1 Write docs"},
+		{
+			"assignee and subtasks",
+			map[string]interface{}{"id": "2", "title": "Ship", "assignee_username": "bob", "nb_subtasks": "3", "nb_completed_subtasks": "1"},
+			time.Now(),
+			"#2 Ship @bob [1/3]",
+		},
+		{"due in UTC", map[string]interface{}{"id": "3", "title": "Pay", "date_due": due}, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), "#3 Pay due 2024-05-31"},
+		{"due in the Kanboard timezone", map[string]interface{}{"id": "3", "title": "Pay", "date_due": due}, time.Date(2024, 5, 1, 0, 0, 0, 0, tokyo), "#3 Pay due 2024-06-01"},
+		{"overdue", map[string]interface{}{"id": "3", "title": "Pay", "date_due": due}, time.Date(2024, 6, 2, 0, 0, 0, 0, tokyo), "#3 Pay due 2024-06-01 (overdue)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taskBadge(tt.task, tt.now); got != tt.want {
+				t.Errorf("taskBadge = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDateExpr(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// Wednesday, 15 May 2024, 10:30 in New York.
+	now := time.Date(2024, 5, 15, 10, 30, 0, 0, loc)
+	sprintEnd := func() (time.Time, error) { return time.Date(2024, 5, 24, 23, 59, 59, 0, loc), nil }
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		hasTime bool
+	}{
+		{"2024-05-20", at(2024, 5, 20, 0, 0), false},
+		{"2024-05-20 14:30", at(2024, 5, 20, 14, 30), true},
+		{"2024-05-20T14:30:00", at(2024, 5, 20, 14, 30), true},
+		{"2024-05-20T14:00:00Z", at(2024, 5, 20, 10, 0), true},
+		{"2024-05-20 14:00 +02:00", at(2024, 5, 20, 8, 0), true},
+		{"2024-05-20 14:00 Europe/Paris", at(2024, 5, 20, 8, 0), true},
+		{"2024-05-20 14:00 UTC", at(2024, 5, 20, 10, 0), true},
+		{"05/20/2024", at(2024, 5, 20, 0, 0), false},
+		{"5/20/2024 14:30", at(2024, 5, 20, 14, 30), true},
+		{"2024/05/20", at(2024, 5, 20, 0, 0), false},
+		{"20.05.2024", at(2024, 5, 20, 0, 0), false},
+		{"2024_05_20", at(2024, 5, 20, 0, 0), false},
+		{"today", at(2024, 5, 15, 0, 0), false},
+		{"Tomorrow", at(2024, 5, 16, 0, 0), false},
+		{"yesterday", at(2024, 5, 14, 0, 0), false},
+		{"now", now, true},
+		{"friday", at(2024, 5, 17, 0, 0), false},
+		{"next friday", at(2024, 5, 17, 0, 0), false},
+		{"next wednesday", at(2024, 5, 22, 0, 0), false},
+		{"this wednesday", at(2024, 5, 15, 0, 0), false},
+		{"last monday", at(2024, 5, 13, 0, 0), false},
+		{"next week", at(2024, 5, 20, 0, 0), false},
+		{"end of week", at(2024, 5, 17, 0, 0), false},
+		{"end of month", at(2024, 5, 31, 0, 0), false},
+		{"next month", at(2024, 6, 1, 0, 0), false},
+		{"end of sprint", at(2024, 5, 24, 0, 0), false},
+		{"in 3 days", at(2024, 5, 18, 10, 30), false},
+		{"in a week", at(2024, 5, 22, 10, 30), false},
+		{"in 2 hours", at(2024, 5, 15, 12, 30), true},
+		{"2 weeks ago", at(2024, 5, 1, 10, 30), false},
+		{"tomorrow 9am", at(2024, 5, 16, 9, 0), true},
+		{"tomorrow at 17:30", at(2024, 5, 16, 17, 30), true},
+		{"next friday 5pm", at(2024, 5, 17, 17, 0), true},
+		{"2024-05-20 at 12am", at(2024, 5, 20, 0, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, hasTime, err := parseDateExpr(tt.value, now, loc, sprintEnd)
+			if err != nil {
+				t.Fatalf("parseDateExpr(%q): %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) || hasTime != tt.hasTime {
+				t.Errorf("parseDateExpr(%q) = %v, %v; want %v, %v", tt.value, got, hasTime, tt.want, tt.hasTime)
+			}
+			if got.Location() != loc {
+				t.Errorf("parseDateExpr(%q) is in %v, want %v", tt.value, got.Location(), loc)
+			}
+		})
+	}
+}
+
+func TestParseDateExprErrors(t *testing.T) {
+	now := time.Date(2024, 5, 15, 10, 30, 0, 0, time.UTC)
+	sprintErr := errors.New("project 1 has no active sprint")
+	tests := []struct {
+		name      string
+		value     string
+		sprintEnd func() (time.Time, error)
+	}{
+		{"garbage", "someday", nil},
+		{"invalid month", "2024-13-01", nil},
+		{"invalid hour", "tomorrow 25:00", nil},
+		{"bare number", "tomorrow 9", nil},
+		{"end of sprint without project", "end of sprint", nil},
+		{"end of sprint without active sprint", "end of sprint", func() (time.Time, error) { return time.Time{}, sprintErr }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _, err := parseDateExpr(tt.value, now, time.UTC, tt.sprintEnd); err == nil {
+				t.Errorf("parseDateExpr(%q) = %v, want an error", tt.value, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// testGraph builds a dependency graph of open tasks from "from -> to" edges.
+func testGraph(weights map[int]float64, edges [][2]int) *dependencyGraph {
+	graph := &dependencyGraph{Nodes: map[int]*dependencyNode{}, Next: map[int][]int{}, Prev: map[int][]int{}}
+	for id, weight := range weights {
+		graph.Nodes[id] = &dependencyNode{ID: id, Open: true, Weight: weight}
+	}
+	for _, edge := range edges {
+		graph.addEdge(edge[0], edge[1])
+	}
+	return graph
+}
+
+func TestDependencyCycles(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges [][2]int
+		want  [][]int
+	}{
+		{"no edges", nil, nil},
+		{"chain", [][2]int{{1, 2}, {2, 3}, {3, 4}}, nil},
+		{"diamond", [][2]int{{1, 2}, {1, 3}, {2, 4}, {3, 4}}, nil},
+		{"two-task cycle", [][2]int{{1, 2}, {2, 1}, {2, 3}}, [][]int{{1, 2}}},
+		{"three-task cycle", [][2]int{{1, 2}, {2, 3}, {3, 1}, {4, 1}}, [][]int{{1, 2, 3}}},
+		{"separate cycles", [][2]int{{1, 2}, {2, 1}, {3, 4}, {4, 5}, {5, 3}}, [][]int{{1, 2}, {3, 4, 5}}},
+		{"duplicate edges", [][2]int{{1, 2}, {1, 2}, {2, 1}}, [][]int{{1, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := testGraph(map[int]float64{1: 1, 2: 1, 3: 1, 4: 1, 5: 1}, tt.edges)
+			got := dependencyCycles(graph)
+			sort.Slice(got, func(i, j int) bool { return got[i][0] < got[j][0] })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dependencyCycles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		weights    map[int]float64
+		edges      [][2]int
+		closed     []int
+		inCycle    []int
+		wantPath   []int
+		wantWeight float64
+	}{
+		{
+			name:     "empty graph",
+			weights:  map[int]float64{},
+			wantPath: []int{},
+		},
+		{
+			name:       "single task",
+			weights:    map[int]float64{7: 3},
+			wantPath:   []int{7},
+			wantWeight: 3,
+		},
+		{
+			name:       "chain",
+			weights:    map[int]float64{1: 1, 2: 2, 3: 3},
+			edges:      [][2]int{{1, 2}, {2, 3}},
+			wantPath:   []int{1, 2, 3},
+			wantWeight: 6,
+		},
+		{
+			name:       "heavier branch of a diamond",
+			weights:    map[int]float64{1: 1, 2: 1, 3: 5, 4: 1},
+			edges:      [][2]int{{1, 2}, {1, 3}, {2, 4}, {3, 4}},
+			wantPath:   []int{1, 3, 4},
+			wantWeight: 7,
+		},
+		{
+			name:       "heavy standalone task beats a light chain",
+			weights:    map[int]float64{1: 1, 2: 1, 3: 10},
+			edges:      [][2]int{{1, 2}},
+			wantPath:   []int{3},
+			wantWeight: 10,
+		},
+		{
+			name:       "closed tasks are skipped",
+			weights:    map[int]float64{1: 5, 2: 1, 3: 1},
+			edges:      [][2]int{{1, 2}, {2, 3}},
+			closed:     []int{1},
+			wantPath:   []int{2, 3},
+			wantWeight: 2,
+		},
+		{
+			name:       "tasks in cycles are skipped",
+			weights:    map[int]float64{1: 5, 2: 5, 3: 1, 4: 1},
+			edges:      [][2]int{{1, 2}, {2, 1}, {2, 3}, {3, 4}},
+			inCycle:    []int{1, 2},
+			wantPath:   []int{3, 4},
+			wantWeight: 2,
+		},
+		{
+			name:       "ties go to the longer chain",
+			weights:    map[int]float64{1: 2, 2: 1, 3: 1},
+			edges:      [][2]int{{2, 3}},
+			wantPath:   []int{2, 3},
+			wantWeight: 2,
+		},
+		{
+			name:       "zero weights still give a path",
+			weights:    map[int]float64{1: 0, 2: 0},
+			edges:      [][2]int{{1, 2}},
+			wantPath:   []int{1, 2},
+			wantWeight: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := testGraph(tt.weights, tt.edges)
+			for _, id := range tt.closed {
+				graph.Nodes[id].Open = false
+			}
+			inCycle := map[int]bool{}
+			for _, id := range tt.inCycle {
+				inCycle[id] = true
+			}
+			path, weight := criticalPath(graph, inCycle)
+			if !reflect.DeepEqual(path, tt.wantPath) || weight != tt.wantWeight {
+				t.Errorf("criticalPath = %v, %v; want %v, %v", path, weight, tt.wantPath, tt.wantWeight)
+			}
+		})
+	}
+}
+
+func TestJoinErrors(t *testing.T) {
+	if err := joinErrors([]error{nil, nil}); err != nil {
+		t.Errorf("joinErrors(no failures) = %v, want nil", err)
+	}
+	err := joinErrors([]error{fmt.Errorf("links of task 1: boom"), nil, fmt.Errorf("links of task 3: boom")})
+	if err == nil || err.Error() != "links of task 1: boom; links of task 3: boom" {
+		t.Errorf("joinErrors = %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestDuplicateScore(t *testing.T) {
+	type task struct {
+		title, description, reference string
+		links                         []string
+	}
+	tests := []struct {
+		name       string
+		a, b       task
+		min, max   float64
+		wantReason string
+	}{
+		{
+			name: "same reference", min: 1, max: 1, wantReason: "same reference gh-12",
+			a: task{title: "Crash on start", reference: "GH-12"},
+			b: task{title: "Something else", reference: " gh-12"},
+		},
+		{
+			name: "same external link", min: 1, max: 1, wantReason: "same external link https://example.com/issues/3",
+			a: task{title: "Crash on start", links: []string{"https://example.com/issues/3/"}},
+			b: task{title: "Something else", links: []string{"https://example.com/issues/3"}},
+		},
+		{
+			name: "same title up to case and punctuation", min: 1, max: 1, wantReason: "same title",
+			a: task{title: "Fix the login page!"},
+			b: task{title: "fix  the LOGIN page"},
+		},
+		{
+			name: "word variants", min: duplicateThreshold, max: 0.99, wantReason: "similar title",
+			a: task{title: "Login page broken"},
+			b: task{title: "Log-in pages broken"},
+		},
+		{
+			name: "unrelated titles", min: 0, max: 0.3, wantReason: "similar title",
+			a: task{title: "Add dark mode"},
+			b: task{title: "Export invoices to CSV"},
+		},
+		{
+			name: "bigrams are built over runes", min: 0.5, max: 0.5, wantReason: "similar title",
+			a: task{title: "日本語"},
+			b: task{title: "日本人"},
+		},
+		{
+			name: "matching descriptions", min: 0.3, max: 0.99, wantReason: "similar description",
+			a: task{title: "Checkout fails", description: "Paying with a saved credit card returns an error 500"},
+			b: task{title: "Payment error", description: "Paying with a saved credit card returns an error 500"},
+		},
+		{
+			name: "short descriptions are ignored", min: 1, max: 1, wantReason: "same title",
+			a: task{title: "Update dependencies", description: "Go modules"},
+			b: task{title: "Update dependencies", description: "npm"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newDuplicateText(tt.a.title, tt.a.description, tt.a.reference, tt.a.links)
+			b := newDuplicateText(tt.b.title, tt.b.description, tt.b.reference, tt.b.links)
+			score, reason := duplicateScore(a, b)
+			if score < tt.min || score > tt.max || reason != tt.wantReason {
+				t.Errorf("duplicateScore = %v, %q; want [%v, %v], %q", score, reason, tt.min, tt.max, tt.wantReason)
+			}
+			if reverse, _ := duplicateScore(b, a); reverse != score {
+				t.Errorf("duplicateScore is not symmetric: %v vs %v", score, reverse)
+			}
+		})
+	}
+}
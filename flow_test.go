@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHistoryStart(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	events := func(at time.Time) []map[string]interface{} {
+		return []map[string]interface{}{{"date_creation": at.Unix()}}
+	}
+	tests := []struct {
+		name   string
+		args   map[string]interface{}
+		events []map[string]interface{}
+		want   time.Time
+	}{
+		{"no history", map[string]interface{}{}, nil, from},
+		{"history covers the range", map[string]interface{}{}, events(from.AddDate(0, 0, -3)), from},
+		{"history starts within the range", map[string]interface{}{}, events(from.AddDate(0, 0, 10)), from.AddDate(0, 0, 10)},
+		{"history starts after the range", map[string]interface{}{}, events(to.AddDate(0, 0, 1)), from},
+		{"explicit from is kept", map[string]interface{}{"from": "2024-05-01"}, events(from.AddDate(0, 0, 10)), from},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tt.args
+			if got := historyStart(request, tt.events, from, to); !got.Equal(tt.want) {
+				t.Errorf("historyStart = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnAt(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC) }
+	// Created on the 1st in column 1; moved to 2 on the 10th and to 3 on the 20th.
+	moves := &taskFlow{Transitions: []columnTransition{{At: day(10), From: 1, To: 2}, {At: day(20), From: 2, To: 3}}}
+	tests := []struct {
+		name       string
+		flow       *taskFlow
+		t          time.Time
+		moved      time.Time
+		wantColumn int
+		wantSince  time.Time
+		wantOK     bool
+	}{
+		{"before the first move", moves, day(5), day(20), 1, day(1), true},
+		{"between moves", moves, day(15), day(20), 2, day(10), true},
+		{"after the last move", moves, day(25), day(20), 3, day(20), true},
+		{"no moves since", nil, day(25), day(20), 3, day(20), true},
+		{"never moved", &taskFlow{}, day(5), day(1), 3, day(1), true},
+		{"moved before the history", nil, day(5), day(20), 0, time.Time{}, false},
+		{"move without a source column", &taskFlow{Transitions: []columnTransition{{At: day(10), To: 2}}}, day(5), day(10), 0, day(1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column, since, ok := tt.flow.columnAt(tt.t, 3, day(1), tt.moved)
+			if column != tt.wantColumn || !since.Equal(tt.wantSince) || ok != tt.wantOK {
+				t.Errorf("columnAt = %d, %v, %v; want %d, %v, %v", column, since, ok, tt.wantColumn, tt.wantSince, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testResolver returns a name resolver whose lookups are already loaded, so it never calls the API.
+func testResolver(loc *time.Location) *nameResolver {
+	return &nameResolver{
+		loc:            loc,
+		users:          map[int]string{1: "Alice", 2: "bob"},
+		projects:       map[int]string{1: "Web"},
+		columns:        map[int]string{3: "Work in progress"},
+		swimlanes:      map[int]string{4: "Default swimlane"},
+		categories:     map[int]string{5: "Bug"},
+		loadedProjects: map[int]bool{1: true},
+		usersLoaded:    true,
+		projectsLoaded: true,
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	tests := []struct {
+		name string
+		item map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "task",
+			item: map[string]interface{}{"id": "7", "project_id": "1", "column_id": "3", "swimlane_id": "4", "category_id": "5", "owner_id": "2", "creator_id": "1", "date_due": "1700000000", "date_completed": "0"},
+			want: map[string]interface{}{
+				"id": "7", "project_id": "1", "column_id": "3", "swimlane_id": "4", "category_id": "5", "owner_id": "2", "creator_id": "1",
+				"date_due": "2023-11-15T00:13:20+02:00", "date_completed": "",
+				"project": "Web", "column": "Work in progress", "swimlane": "Default swimlane", "category": "Bug",
+				"assignee": "bob", "owner": "bob", "creator": "Alice",
+			},
+		},
+		{
+			name: "payload names win over lookups",
+			item: map[string]interface{}{"project_id": "1", "project_name": "Renamed", "column_id": "3", "column_title": "Doing", "swimlane_id": "4", "swimlane_name": "Lane", "owner_id": "0"},
+			want: map[string]interface{}{
+				"project_id": "1", "project_name": "Renamed", "column_id": "3", "column_title": "Doing", "swimlane_id": "4", "swimlane_name": "Lane", "owner_id": "0",
+				"project": "Renamed", "column": "Doing", "swimlane": "Lane", "assignee": "", "owner": "",
+			},
+		},
+		{
+			name: "search result assignee",
+			item: map[string]interface{}{"id": "8", "assignee_name": "Carol"},
+			want: map[string]interface{}{"id": "8", "assignee_name": "Carol", "assignee": "Carol"},
+		},
+		{
+			name: "subtask or comment user",
+			item: map[string]interface{}{"id": "9", "user_id": "1", "username": "admin", "name": ""},
+			want: map[string]interface{}{"id": "9", "user_id": "1", "username": "admin", "name": "", "assignee": "admin", "author": "admin"},
+		},
+		{
+			name: "unknown user",
+			item: map[string]interface{}{"user_id": "42"},
+			want: map[string]interface{}{"user_id": "42", "assignee": "", "author": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testResolver(loc).normalize(context.Background(), tt.item)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalize(%v)\n got %v\nwant %v", tt.item, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderListEmpty(t *testing.T) {
+	kc := newKanboardClient("http://127.0.0.1:0/jsonrpc.php", "key", "", "")
+	var nothing []interface{}
+	for _, fields := range [][]string{nil, {"id", "title"}} {
+		text, err := kc.renderList(context.Background(), nothing, "task", "json", fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text != "[]" {
+			t.Errorf("renderList(nil, fields %v) = %q, want []", fields, text)
+		}
+	}
+}
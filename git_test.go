@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommitReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []commitReference
+	}{
+		{"no references", "Refactor the login form", nil},
+		{"hash reference", "Tidy up #12", []commitReference{{Text: "#12", TaskID: 12, Key: "#12", Bare: true}}},
+		{"prefix reference", "KB-7: tidy up", []commitReference{{Text: "KB-7", TaskID: 7, Key: "#7"}}},
+		{"prefix is case-insensitive", "see kb-7", []commitReference{{Text: "kb-7", TaskID: 7, Key: "#7"}}},
+		{"foreign key", "Port JIRA-42", []commitReference{{Text: "JIRA-42", Key: "JIRA-42"}}},
+		{"fixes keyword", "Fixes #12", []commitReference{{Text: "#12", TaskID: 12, Key: "#12", Fixes: true, Bare: true}}},
+		{"fix with colon", "fix: KB-3", []commitReference{{Text: "KB-3", TaskID: 3, Key: "#3", Fixes: true}}},
+		{"closes and resolved", "Closes #1, resolved #2", []commitReference{
+			{Text: "#1", TaskID: 1, Key: "#1", Fixes: true, Bare: true},
+			{Text: "#2", TaskID: 2, Key: "#2", Fixes: true, Bare: true},
+		}},
+		{"keyword must precede the reference", "Fix the parser for #12", []commitReference{{Text: "#12", TaskID: 12, Key: "#12", Bare: true}}},
+		{"keyword inside a word", "Prefixes #12", []commitReference{{Text: "#12", TaskID: 12, Key: "#12", Bare: true}}},
+		{"duplicates merge", "See #5 and KB-5\n\nFixes #5", []commitReference{{Text: "#5", TaskID: 5, Key: "#5", Fixes: true}}},
+		{"bare duplicates stay bare", "See #5\n\nFixes #5", []commitReference{{Text: "#5", TaskID: 5, Key: "#5", Fixes: true, Bare: true}}},
+		{"lower-case keys are ignored", "Handle utf-8 names and sha-256 digests", nil},
+		{"mixed-case keys are ignored", "Support Utf-8", nil},
+		{"html entities and urls are ignored", "Quote &#39; and link a/#4 or issue#4", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitReferences(tt.message, "KB"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commitReferences(%q) = %+v, want %+v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
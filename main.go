@@ -3,14 +3,23 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/csv"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
+	"math"
+	"net"
 	"net/http"
 	"os"
+	"os/exec"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -23,6 +32,8 @@ func main() {
 		"KanboardMCP",
 		"1.0.0",
 		server.WithToolCapabilities(false),
+		server.WithResourceCapabilities(false, false),
+		server.WithLogging(),
 	)
 
 	var tool mcp.Tool
@@ -50,8 +61,24 @@ func main() {
 
 	kbClient := newKanboardClient(apiEndpoint, apiKey, kbUsername, kbPassword)
 
+	// Kanboard webhooks received in HTTP mode are appended to this log. A stdio server only
+	// reads it, so it only has one when KANBOARD_EVENT_LOG points at the log of an HTTP server.
+	httpAddr := os.Getenv("KANBOARD_MCP_HTTP_ADDR")
+	eventLogPath := os.Getenv("KANBOARD_EVENT_LOG")
+	if eventLogPath == "" && httpAddr != "" {
+		eventLogPath = "kanboard-events.jsonl"
+	}
+	if eventLogPath != "" {
+		kbClient.events = newEventLog(eventLogPath)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "scan-git" {
+		os.Exit(runScanGit(kbClient, os.Args[2:]))
+	}
+
 	tool = mcp.NewTool("get_projects",
 		mcp.WithDescription("List all projects"),
+		withListFormat(),
 	)
 	s.AddTool(tool, kbClient.getProjectsHandler)
 
@@ -97,6 +124,7 @@ func main() {
 			mcp.Required(),
 			mcp.Description("Name of the project to get tasks from"),
 		),
+		withListFormat(),
 	)
 	s.AddTool(tool, kbClient.getTasksHandler)
 
@@ -123,7 +151,7 @@ func main() {
 			mcp.Description("ID of the task creator (optional)"),
 		),
 		mcp.WithString("date_due",
-			mcp.Description("Due date in YYYY-MM-DD HH:MM format (optional)"),
+			mcp.Description("Due date: YYYY-MM-DD HH:MM, ISO 8601 or e.g. 'next Friday 17:00', 'in 3 days', 'end of sprint' (optional)"),
 		),
 		mcp.WithString("description",
 			mcp.Description("Markdown content for the task description (optional)"),
@@ -163,7 +191,11 @@ func main() {
 			mcp.Description("List of tags (array of strings) (optional)"),
 		),
 		mcp.WithString("date_started",
-			mcp.Description("Start date in YYYY-MM-DD HH:MM format (optional)"),
+			mcp.Description("Start date: YYYY-MM-DD HH:MM, ISO 8601 or e.g. 'tomorrow 9am' (optional)"),
+		),
+		mcp.WithString("dedupe",
+			mcp.Enum("off", "warn", "refuse"),
+			mcp.Description("Check the project's open tasks for a similar one first: warn returns them along with the new task ID, refuse doesn't create the task; defaults to off (optional)"),
 		),
 	)
 	s.AddTool(tool, kbClient.createTaskHandler)
@@ -184,7 +216,7 @@ func main() {
 			mcp.Description("New owner ID for the task (optional)"),
 		),
 		mcp.WithString("date_due",
-			mcp.Description("New due date in YYYY-MM-DD HH:MM format (optional)"),
+			mcp.Description("New due date: YYYY-MM-DD HH:MM, ISO 8601 or e.g. 'next Friday 17:00', 'in 3 days', 'end of sprint' (optional)"),
 		),
 		mcp.WithString("description",
 			mcp.Description("New Markdown content for the task description (optional)"),
@@ -221,8 +253,9 @@ func main() {
 			mcp.Description("New list of tags (array of strings) (optional)"),
 		),
 		mcp.WithString("date_started",
-			mcp.Description("New start date in YYYY-MM-DD HH:MM format (optional)"),
+			mcp.Description("New start date: YYYY-MM-DD HH:MM, ISO 8601 or e.g. 'tomorrow 9am' (optional)"),
 		),
+		withClear("owner_id", "date_due", "date_started", "description", "category_id", "score", "priority", "reference", "tags"),
 	)
 	s.AddTool(tool, kbClient.updateTaskHandler)
 
@@ -266,11 +299,38 @@ func main() {
 			mcp.Required(),
 			mcp.Description("ID of the swimlane to move the task to"),
 		),
+		mcp.WithBoolean("override_wip_limit",
+			mcp.Description("Move even if the target column is at its WIP limit (optional)"),
+		),
 	)
 	s.AddTool(tool, kbClient.moveTaskPositionHandler)
 
+	tool = mcp.NewTool("move_task",
+		mcp.WithDescription("Move a task to a column by name or ID, keeping its swimlane unless told otherwise"),
+		mcp.WithNumber("task_id",
+			mcp.Required(),
+			mcp.Description("ID of the task to move"),
+		),
+		mcp.WithString("column",
+			mcp.Required(),
+			mcp.Description("Title or ID of the column to move the task to"),
+		),
+		mcp.WithString("position",
+			mcp.Description("top, bottom (default) or after:<task id> (optional)"),
+		),
+		mcp.WithString("swimlane",
+			mcp.Description("Name or ID of the swimlane to move the task to, defaults to its current swimlane (optional)"),
+		),
+		mcp.WithBoolean("override_wip_limit",
+			mcp.Description("Move even if the target column is at its WIP limit (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.moveTaskHandler)
+
 	tool = mcp.NewTool("get_users",
 		mcp.WithDescription("List all system users"),
+		withListFormat(),
+		withPagination(),
 	)
 	s.AddTool(tool, kbClient.getUsersHandler)
 
@@ -341,6 +401,7 @@ func main() {
 		mcp.WithString("role",
 			mcp.Description("New role (app-admin, app-manager, app-user) (optional)"),
 		),
+		withClear("name", "email"),
 	)
 	s.AddTool(tool, kbClient.updateUserHandler)
 
@@ -401,7 +462,7 @@ func main() {
 		),
 		mcp.WithString("due_date",
 			mcp.Required(),
-			mcp.Description("Due date in YYYY-MM-DD format"),
+			mcp.Description("Due date: YYYY-MM-DD, YYYY-MM-DD HH:MM, ISO 8601 or e.g. 'next Friday', 'in 3 days', 'end of sprint'"),
 		),
 	)
 	s.AddTool(tool, kbClient.setTaskDueDateHandler)
@@ -435,6 +496,7 @@ func main() {
 			mcp.Required(),
 			mcp.Description("ID of the task to get comments for"),
 		),
+		withListFormat(),
 	)
 	s.AddTool(tool, kbClient.getTaskCommentsHandler)
 
@@ -519,6 +581,7 @@ func main() {
 
 	tool = mcp.NewTool("get_my_overdue_tasks",
 		mcp.WithDescription("Get my overdue tasks"),
+		withListFormat(),
 	)
 	s.AddTool(tool, kbClient.getMyOverdueTasksHandler)
 
@@ -582,6 +645,7 @@ func main() {
 		mcp.WithString("dependency",
 			mcp.Description("New dependency for the external link"),
 		),
+		withClear("title", "dependency"),
 	)
 	s.AddTool(tool, kbClient.updateExternalTaskLinkHandler)
 
@@ -672,6 +736,7 @@ func main() {
 		mcp.WithString("description",
 			mcp.Description("New description for the column"),
 		),
+		withClear("task_limit", "description"),
 	)
 	s.AddTool(tool, kbClient.updateColumnHandler)
 
@@ -747,6 +812,7 @@ func main() {
 		mcp.WithString("color_id",
 			mcp.Description("Color ID for the category (e.g., 'blue', 'green')"),
 		),
+		withClear("color_id"),
 	)
 	s.AddTool(tool, kbClient.updateCategoryHandler)
 
@@ -857,6 +923,7 @@ func main() {
 		mcp.WithString("description",
 			mcp.Description("New description for the swimlane (optional)"),
 		),
+		withClear("description"),
 	)
 	s.AddTool(tool, kbClient.updateSwimlaneHandler)
 
@@ -981,6 +1048,7 @@ func main() {
 		mcp.WithString("external_id",
 			mcp.Description("New external ID for the group (optional)"),
 		),
+		withClear("external_id"),
 	)
 	s.AddTool(tool, kbClient.updateGroupHandler)
 
@@ -1239,6 +1307,8 @@ func main() {
 
 	tool = mcp.NewTool("get_all_projects",
 		mcp.WithDescription("Get all available projects"),
+		withListFormat(),
+		withPagination(),
 	)
 	s.AddTool(tool, kbClient.getAllProjectsHandler)
 
@@ -1278,6 +1348,7 @@ func main() {
 		mcp.WithString("email",
 			mcp.Description("New project email address (optional)"),
 		),
+		withClear("description", "owner_id", "identifier", "start_date", "end_date", "email"),
 	)
 	s.AddTool(tool, kbClient.updateProjectHandler)
 
@@ -1332,6 +1403,7 @@ func main() {
 			mcp.Required(),
 			mcp.Description("ID of the project to get activity for"),
 		),
+		withListFormat(),
 	)
 	s.AddTool(tool, kbClient.getProjectActivityHandler)
 
@@ -1342,6 +1414,8 @@ func main() {
 			mcp.WithNumberItems(),
 			mcp.Description("Array of project IDs to get activities for"),
 		),
+		withListFormat(),
+		withPagination(),
 	)
 	s.AddTool(tool, kbClient.getProjectActivitiesHandler)
 
@@ -1637,6 +1711,7 @@ func main() {
 			mcp.Required(),
 			mcp.Description("ID of the task to get subtasks for"),
 		),
+		withListFormat(),
 	)
 	s.AddTool(tool, kbClient.getAllSubtasksHandler)
 
@@ -1665,6 +1740,7 @@ func main() {
 		mcp.WithNumber("status",
 			mcp.Description("New status of the subtask (0: Todo, 1: In Progress, 2: Done) (optional)"),
 		),
+		withClear("user_id", "time_estimated", "time_spent", "status"),
 	)
 	s.AddTool(tool, kbClient.updateSubtaskHandler)
 
@@ -1770,6 +1846,7 @@ func main() {
 		mcp.WithNumber("color_id",
 			mcp.Description("New color ID for the tag (optional)"),
 		),
+		withClear("color_id"),
 	)
 	s.AddTool(tool, kbClient.updateTagHandler)
 
@@ -1993,11 +2070,14 @@ func main() {
 			mcp.Required(),
 			mcp.Description("The value 1 for active tasks and 0 for inactive"),
 		),
+		withListFormat(),
+		withPagination(),
 	)
 	s.AddTool(tool, kbClient.getAllTasksHandler)
 
 	tool = mcp.NewTool("get_overdue_tasks",
 		mcp.WithDescription("Get all overdue tasks"),
+		withListFormat(),
 	)
 	s.AddTool(tool, kbClient.getOverdueTasksHandler)
 
@@ -2007,6 +2087,7 @@ func main() {
 			mcp.Required(),
 			mcp.Description("ID of the project"),
 		),
+		withListFormat(),
 	)
 	s.AddTool(tool, kbClient.getOverdueTasksByProjectHandler)
 
@@ -2088,6 +2169,8 @@ func main() {
 			mcp.Required(),
 			mcp.Description("Search query string"),
 		),
+		withListFormat(),
+		withPagination(),
 	)
 	s.AddTool(tool, kbClient.searchTasksHandler)
 
@@ -2104,11 +2187,11 @@ func main() {
 		),
 		mcp.WithString("start_date",
 			mcp.Required(),
-			mcp.Description("Start date of the sprint (YYYY-MM-DD)"),
+			mcp.Description("Start date of the sprint (YYYY-MM-DD or e.g. 'next Monday')"),
 		),
 		mcp.WithString("end_date",
 			mcp.Required(),
-			mcp.Description("End date of the sprint (YYYY-MM-DD)"),
+			mcp.Description("End date of the sprint (YYYY-MM-DD or e.g. 'in 2 weeks')"),
 		),
 	)
 	s.AddTool(tool, kbClient.createSprintHandler)
@@ -2132,10 +2215,10 @@ func main() {
 			mcp.Description("New name for the sprint (optional)"),
 		),
 		mcp.WithString("start_date",
-			mcp.Description("New start date for the sprint (YYYY-MM-DD) (optional)"),
+			mcp.Description("New start date for the sprint (YYYY-MM-DD or e.g. 'next Monday') (optional)"),
 		),
 		mcp.WithString("end_date",
-			mcp.Description("New end date for the sprint (YYYY-MM-DD) (optional)"),
+			mcp.Description("New end date for the sprint (YYYY-MM-DD or e.g. 'end of month') (optional)"),
 		),
 		mcp.WithString("sprint_goal",
 			mcp.Description("New goal for the sprint (optional)"),
@@ -2146,6 +2229,7 @@ func main() {
 		mcp.WithBoolean("is_active",
 			mcp.Description("Whether the sprint is active (optional)"),
 		),
+		withClear("sprint_goal", "start_date", "end_date"),
 	)
 	s.AddTool(tool, kbClient.updateSprintHandler)
 
@@ -2167,129 +2251,721 @@ func main() {
 	)
 	s.AddTool(tool, kbClient.getAllSprintsByProjectHandler)
 
-	// Start the stdio server
-	if err := server.ServeStdio(s); err != nil {
-		fmt.Printf("Server error: %v\n", err)
-	}
-}
+	tool = mcp.NewTool("query_tasks",
+		mcp.WithDescription("Query tasks across one or many projects with filters, sorting and grouping"),
+		mcp.WithArray("project_ids",
+			mcp.WithNumberItems(),
+			mcp.Description("IDs of the projects to search, defaults to all visible projects (optional)"),
+		),
+		mcp.WithArray("project_names",
+			mcp.WithStringItems(),
+			mcp.Description("Names of the projects to search (optional)"),
+		),
+		mcp.WithString("status",
+			mcp.Enum("open", "closed", "all"),
+			mcp.Description("Task status to include, defaults to open (optional)"),
+		),
+		mcp.WithString("assignee",
+			mcp.Description("Assignee user ID, username, full name, 'me' or 'nobody' (optional)"),
+		),
+		mcp.WithString("column",
+			mcp.Description("Column name (optional)"),
+		),
+		mcp.WithString("swimlane",
+			mcp.Description("Swimlane name (optional)"),
+		),
+		mcp.WithString("category",
+			mcp.Description("Category name (optional)"),
+		),
+		mcp.WithArray("tags",
+			mcp.WithStringItems(),
+			mcp.Description("Tags the task must carry (optional)"),
+		),
+		mcp.WithString("tags_match",
+			mcp.Enum("any", "all"),
+			mcp.Description("Whether the task needs any or all of the tags, defaults to any (optional)"),
+		),
+		mcp.WithNumber("priority_min",
+			mcp.Description("Minimum priority (optional)"),
+		),
+		mcp.WithNumber("priority_max",
+			mcp.Description("Maximum priority (optional)"),
+		),
+		mcp.WithNumber("score_min",
+			mcp.Description("Minimum complexity score (optional)"),
+		),
+		mcp.WithNumber("score_max",
+			mcp.Description("Maximum complexity score (optional)"),
+		),
+		mcp.WithString("due_before",
+			mcp.Description("Only tasks due before this date (optional)"),
+		),
+		mcp.WithString("due_after",
+			mcp.Description("Only tasks due after this date (optional)"),
+		),
+		mcp.WithString("modified_since",
+			mcp.Description("Only tasks modified since this date (optional)"),
+		),
+		mcp.WithBoolean("has_open_subtasks",
+			mcp.Description("Only tasks with (true) or without (false) unfinished subtasks (optional)"),
+		),
+		mcp.WithArray("metadata",
+			mcp.WithStringItems(),
+			mcp.Description("Metadata filters in key=value form; use key=* to only require the key (optional)"),
+		),
+		mcp.WithString("text",
+			mcp.Description("Text to find in the title, description or reference (optional)"),
+		),
+		mcp.WithString("sort_by",
+			mcp.Description("Sort by due_date, priority, position, date_modified, created, id or title (optional)"),
+		),
+		mcp.WithString("sort_order",
+			mcp.Enum("asc", "desc"),
+			mcp.Description("Sort order (optional)"),
+		),
+		mcp.WithString("group_by",
+			mcp.Enum("project", "column", "swimlane", "assignee", "category", "priority"),
+			mcp.Description("Group the results (optional)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of tasks to return (optional)"),
+		),
+		withListFormat(),
+	)
+	s.AddTool(tool, kbClient.queryTasksHandler)
 
-type kanboardClient struct {
-	apiEndpoint string
-	apiKey      string
-	username    string
-	password    string
-}
+	tool = mcp.NewTool("search_all",
+		mcp.WithDescription("Search tasks across all projects visible to the API user and rank the merged results. When some projects cannot be searched, the json and compact formats return {items, failures} instead of the bare list"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("Kanboard search query, e.g. 'login bug' or 'assignee:me due:tomorrow'"),
+		),
+		mcp.WithBoolean("include_comments",
+			mcp.Description("Also match comment bodies of open tasks (optional)"),
+		),
+		mcp.WithBoolean("include_subtasks",
+			mcp.Description("Also match subtask titles of open tasks (optional)"),
+		),
+		mcp.WithNumber("concurrency",
+			mcp.Description("Maximum number of projects searched in parallel, defaults to 4, at most 16 (optional)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of results, defaults to 50, 0 for all (optional)"),
+		),
+		withListFormat(),
+	)
+	s.AddTool(tool, kbClient.searchAllHandler)
 
-func newKanboardClient(apiEndpoint, apiKey, username, password string) *kanboardClient {
-	return &kanboardClient{
-		apiEndpoint: apiEndpoint,
-		apiKey:      apiKey,
-		username:    username,
-		password:    password,
-	}
-}
+	tool = mcp.NewTool("get_task_full",
+		mcp.WithDescription("Get a task with its comments, subtasks, links, external links, tags, metadata and files in one call"),
+		mcp.WithNumber("task_id",
+			mcp.Required(),
+			mcp.Description("ID of the task to get"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("json", "markdown"),
+			mcp.Description("Output format, defaults to json (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.getTaskFullHandler)
 
-// APIResponse represents the standard Kanboard JSON-RPC response structure
-type APIResponse struct {
-	Jsonrpc string      `json:"jsonrpc"`
-	ID      int         `json:"id"`
-	Result  interface{} `json:"result"`
-	Error   *APIError   `json:"error"`
-}
+	tool = mcp.NewTool("render_board",
+		mcp.WithDescription("Render a project board as a compact Markdown kanban view with WIP counts"),
+		mcp.WithNumber("project_id",
+			mcp.Required(),
+			mcp.Description("ID of the project whose board to render"),
+		),
+		mcp.WithString("layout",
+			mcp.Enum("sections", "table"),
+			mcp.Description("Columns as sections (default) or as table columns (optional)"),
+		),
+		mcp.WithString("swimlane",
+			mcp.Description("Only render this swimlane (optional)"),
+		),
+		mcp.WithString("assignee",
+			mcp.Description("Only show tasks of this user ID, username, 'me' or 'nobody' (optional)"),
+		),
+		mcp.WithString("tag",
+			mcp.Description("Only show tasks carrying this tag (optional)"),
+		),
+		mcp.WithNumber("sprint_id",
+			mcp.Description("Only show tasks of this sprint (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.renderBoardHandler)
 
-// APIError represents a Kanboard API error response
-type APIError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
+	tool = mcp.NewTool("flow_metrics",
+		mcp.WithDescription("Compute lead time, cycle time and weekly throughput from project activity events. Kanboard only returns a project's latest activity events (about 50), so by default the period starts no earlier than the oldest of them; an explicit 'from' before it is kept and the output warns where the history starts"),
+		mcp.WithNumber("project_id",
+			mcp.Required(),
+			mcp.Description("ID of the project to analyze"),
+		),
+		mcp.WithString("from",
+			mcp.Description("Start of the period (tasks closed on or after), defaults to 30 days ago or the oldest activity event, whichever is later (optional)"),
+		),
+		mcp.WithString("to",
+			mcp.Description("End of the period, defaults to now (optional)"),
+		),
+		mcp.WithString("start_column",
+			mcp.Description("Column whose entry starts the cycle time, defaults to the second column (optional)"),
+		),
+		mcp.WithString("group_by",
+			mcp.Enum("swimlane", "category", "assignee"),
+			mcp.Description("Compute metrics per swimlane, category or assignee (optional)"),
+		),
+		mcp.WithBoolean("include_tasks",
+			mcp.Description("Include per-task lead and cycle times (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("json", "markdown"),
+			mcp.Description("Output format, defaults to json (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.flowMetricsHandler)
 
-// RequestConfig holds configuration for API requests
-type RequestConfig struct {
-	MaxRetries    int
-	RetryDelay    time.Duration
-	Timeout       time.Duration
-	EnableLogging bool
-}
+	tool = mcp.NewTool("cumulative_flow",
+		mcp.WithDescription("Daily task counts per column (cumulative flow diagram data, keyed by column ID in JSON) with bottleneck detection, rebuilt from project activity. Kanboard only returns a project's latest activity events (about 50), so by default the period starts no earlier than the oldest of them, tasks whose column on a day predates the history are counted as 'unknown', and an explicit 'from' before the history adds a warning"),
+		mcp.WithNumber("project_id",
+			mcp.Required(),
+			mcp.Description("ID of the project to analyze"),
+		),
+		mcp.WithString("from",
+			mcp.Description("First day of the period, defaults to 14 days ago or the oldest activity event, whichever is later (optional)"),
+		),
+		mcp.WithString("to",
+			mcp.Description("Last day of the period, defaults to today (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("json", "csv"),
+			mcp.Description("Output format, defaults to json (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.cumulativeFlowHandler)
 
-// DefaultRequestConfig returns default configuration for API requests
-func DefaultRequestConfig() *RequestConfig {
-	return &RequestConfig{
-		MaxRetries:    3,
-		RetryDelay:    time.Second * 2,
-		Timeout:       time.Second * 30,
-		EnableLogging: true,
-	}
-}
+	tool = mcp.NewTool("sprint_report",
+		mcp.WithDescription("Sprint burndown, committed vs completed points, carry-over tasks and rolling velocity, rendered as Markdown for a retro"),
+		mcp.WithNumber("sprint_id",
+			mcp.Required(),
+			mcp.Description("ID of the sprint to report on"),
+		),
+		mcp.WithString("unit",
+			mcp.Enum("score", "hours"),
+			mcp.Description("Size tasks by complexity score (default) or estimated hours (optional)"),
+		),
+		mcp.WithNumber("velocity_sprints",
+			mcp.Description("Number of completed sprints in the rolling velocity, up to and including this one once it is completed, defaults to 3 (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("markdown", "json"),
+			mcp.Description("Output format, defaults to markdown (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.sprintReportHandler)
 
-func (kc *kanboardClient) callKanboardAPI(ctx context.Context, method string, params interface{}) (interface{}, error) {
-	return kc.callKanboardAPIWithConfig(ctx, method, params, DefaultRequestConfig())
-}
+	tool = mcp.NewTool("add_tasks_to_sprint",
+		mcp.WithDescription("Add tasks to a sprint, through the ScrumSprint plugin API when available and task metadata otherwise"),
+		mcp.WithNumber("sprint_id",
+			mcp.Required(),
+			mcp.Description("ID of the sprint"),
+		),
+		mcp.WithArray("task_ids",
+			mcp.Required(),
+			mcp.WithNumberItems(),
+			mcp.Description("IDs of the tasks to add"),
+		),
+	)
+	s.AddTool(tool, kbClient.addTasksToSprintHandler)
 
-func (kc *kanboardClient) callKanboardAPIWithConfig(ctx context.Context, method string, params interface{}, config *RequestConfig) (interface{}, error) {
-	if config == nil {
-		config = DefaultRequestConfig()
-	}
+	tool = mcp.NewTool("remove_tasks_from_sprint",
+		mcp.WithDescription("Remove tasks from a sprint, returning them to the backlog"),
+		mcp.WithNumber("sprint_id",
+			mcp.Required(),
+			mcp.Description("ID of the sprint"),
+		),
+		mcp.WithArray("task_ids",
+			mcp.Required(),
+			mcp.WithNumberItems(),
+			mcp.Description("IDs of the tasks to remove"),
+		),
+	)
+	s.AddTool(tool, kbClient.removeTasksFromSprintHandler)
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: config.Timeout,
-	}
+	tool = mcp.NewTool("get_sprint_tasks",
+		mcp.WithDescription("List the tasks that were added to a sprint"),
+		mcp.WithNumber("sprint_id",
+			mcp.Required(),
+			mcp.Description("ID of the sprint"),
+		),
+		withListFormat(),
+	)
+	s.AddTool(tool, kbClient.getSprintTasksHandler)
 
-	var lastErr error
-	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			if config.EnableLogging {
-				fmt.Printf("Retrying API call to %s (attempt %d/%d)\n", method, attempt+1, config.MaxRetries+1)
-			}
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(config.RetryDelay):
-			}
-		}
+	tool = mcp.NewTool("close_sprint",
+		mcp.WithDescription("Mark a sprint completed and move its unfinished tasks to the next sprint or the backlog"),
+		mcp.WithNumber("sprint_id",
+			mcp.Required(),
+			mcp.Description("ID of the sprint to close"),
+		),
+		mcp.WithString("carry_over",
+			mcp.Enum("next", "backlog"),
+			mcp.Description("Where unfinished tasks go: the next sprint (default, falls back to the backlog when there is none) or the backlog (optional)"),
+		),
+		mcp.WithNumber("next_sprint_id",
+			mcp.Description("Sprint to carry unfinished tasks into instead of the next one by start date (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.closeSprintHandler)
 
-		result, err := kc.executeAPIRequest(ctx, client, method, params, config)
-		if err == nil {
-			return result, nil
-		}
+	tool = mcp.NewTool("timesheet",
+		mcp.WithDescription("Aggregate tracked time and estimates per user, project and day or week, with estimate-vs-actual variance. Hours come from subtask time tracking (for the subtask assignee and the filtered user) and each task's estimate is counted once. Time is dated from project activity, of which Kanboard only returns the latest events (about 50): time it can't date is reported under an 'undated' period with a warning"),
+		mcp.WithNumber("project_id",
+			mcp.Description("Only include this project, defaults to every project you can see (optional)"),
+		),
+		mcp.WithString("user",
+			mcp.Description("Only include time of this user ID, username or 'me' (optional)"),
+		),
+		mcp.WithString("from",
+			mcp.Description("Start of the period, defaults to 30 days ago (optional)"),
+		),
+		mcp.WithString("to",
+			mcp.Description("End of the period, defaults to now (optional)"),
+		),
+		mcp.WithString("period",
+			mcp.Enum("day", "week"),
+			mcp.Description("Group time per day or per ISO week (default) (optional)"),
+		),
+		mcp.WithBoolean("by_task",
+			mcp.Description("Break rows down per task, e.g. for invoicing (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("json", "csv", "markdown"),
+			mcp.Description("Output format, defaults to json (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.timesheetHandler)
 
-		lastErr = err
-		
-		// Don't retry on authentication or validation errors
-		if isNonRetryableError(err) {
-			break
-		}
-	}
+	tool = mcp.NewTool("start_timer",
+		mcp.WithDescription("Start a subtask timer for yourself by subtask ID, or by task ID and subtask title"),
+		withTimerTarget(),
+	)
+	s.AddTool(tool, kbClient.startTimerHandler)
 
-	return nil, fmt.Errorf("API call failed after %d attempts: %w", config.MaxRetries+1, lastErr)
-}
+	tool = mcp.NewTool("stop_timer",
+		mcp.WithDescription("Stop a subtask timer; without a subtask or task, stops every timer you have running"),
+		withTimerTarget(),
+	)
+	s.AddTool(tool, kbClient.stopTimerHandler)
 
-func (kc *kanboardClient) executeAPIRequest(ctx context.Context, client *http.Client, method string, params interface{}, config *RequestConfig) (interface{}, error) {
-	// Validate inputs
-	if method == "" {
-		return nil, fmt.Errorf("method cannot be empty")
-	}
+	tool = mcp.NewTool("switch_timer",
+		mcp.WithDescription("Stop your running timers and start one on another subtask"),
+		withTimerTarget(),
+	)
+	s.AddTool(tool, kbClient.switchTimerHandler)
 
-	// Prepare request body
-	requestBody := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  method,
-		"id":      generateRequestID(),
-		"params":  params,
-	}
+	tool = mcp.NewTool("running_timers",
+		mcp.WithDescription("List your running subtask timers, flag forgotten ones (running overnight or too long) and optionally stop them"),
+		mcp.WithString("user",
+			mcp.Description("User ID or username, defaults to the current user (optional)"),
+		),
+		mcp.WithNumber("max_hours",
+			mcp.Description("Running time after which a timer counts as forgotten, defaults to 10 (optional)"),
+		),
+		mcp.WithString("stop",
+			mcp.Enum("none", "forgotten", "all"),
+			mcp.Description("Which running timers to stop, defaults to none (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.runningTimersHandler)
 
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
-	}
+	tool = mcp.NewTool("dependency_graph",
+		mcp.WithDescription("Build the task dependency graph of a project from blocks / is blocked by / depends on links, with cycles, blocked tasks and the critical path, as JSON, Mermaid or Graphviz DOT"),
+		mcp.WithNumber("project_id",
+			mcp.Required(),
+			mcp.Description("ID of the project to analyze"),
+		),
+		mcp.WithString("weight",
+			mcp.Enum("score", "estimate"),
+			mcp.Description("Weigh tasks on the critical path by complexity score (default) or estimated hours (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("json", "mermaid", "dot"),
+			mcp.Description("Output format, defaults to json (optional)"),
+		),
+		mcp.WithBoolean("include_closed",
+			mcp.Description("Also draw closed tasks (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.dependencyGraphHandler)
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", kc.apiEndpoint, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
+	tool = mcp.NewTool("link_tasks",
+		mcp.WithDescription("Link two tasks by relation label (e.g. 'blocks', 'is blocked by', 'relates to', 'duplicates'), returning the existing link instead of creating a duplicate"),
+		mcp.WithNumber("task_id",
+			mcp.Required(),
+			mcp.Description("ID of the task the relation is read from"),
+		),
+		mcp.WithString("relation",
+			mcp.Required(),
+			mcp.Description("Link label, e.g. 'blocks' in 'task_id blocks other_task_id'"),
+		),
+		mcp.WithNumber("other_task_id",
+			mcp.Required(),
+			mcp.Description("ID of the linked task"),
+		),
+	)
+	s.AddTool(tool, kbClient.linkTasksHandler)
 
-	// Set headers
+	tool = mcp.NewTool("unlink_tasks",
+		mcp.WithDescription("Remove the links between two tasks, optionally only those with a given relation label"),
+		mcp.WithNumber("task_id",
+			mcp.Required(),
+			mcp.Description("ID of the first task"),
+		),
+		mcp.WithNumber("other_task_id",
+			mcp.Required(),
+			mcp.Description("ID of the linked task"),
+		),
+		mcp.WithString("relation",
+			mcp.Description("Only remove links with this label (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.unlinkTasksHandler)
+
+	tool = mcp.NewTool("epic_tree",
+		mcp.WithDescription("Show the parent/child (epic) hierarchy of a project built from 'is a parent of' / 'is a child of' links, rolling up completion, open subtasks, score, estimated time and the latest due date of each parent's descendants"),
+		mcp.WithNumber("project_id",
+			mcp.Required(),
+			mcp.Description("ID of the project"),
+		),
+		mcp.WithNumber("task_id",
+			mcp.Description("Only show the tree below this parent task (optional)"),
+		),
+		mcp.WithBoolean("auto_close",
+			mcp.Description("Close every open parent whose children are all closed (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("markdown", "json"),
+			mcp.Description("Output format, defaults to an indented markdown tree (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.epicTreeHandler)
+
+	tool = mcp.NewTool("scan_git_commits",
+		mcp.WithDescription("Scan a local git repository's log for task references (#123, KB-123, or task references like ABC-123), comment on and link the referenced tasks, and with close set, close tasks mentioned with fixes/closes/resolves. Bare #123 references need project_id. Rescans skip commits already recorded on a task"),
+		mcp.WithString("repo_path",
+			mcp.Description("Path of the git repository, defaults to the current directory (optional)"),
+		),
+		mcp.WithString("revision",
+			mcp.Description("Revision range to scan, e.g. 'v1.2.0..HEAD' (optional)"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only scan commits more recent than this, in git date syntax such as '2 weeks ago' (optional)"),
+		),
+		mcp.WithNumber("max_count",
+			mcp.Description("Maximum number of commits to scan, defaults to 100 (optional)"),
+		),
+		mcp.WithNumber("project_id",
+			mcp.Description("Only update tasks of this project; also resolves ABC-123 task references in it. Bare #123 references are skipped without it, as they often point at issues or pull requests (optional)"),
+		),
+		mcp.WithString("prefix",
+			mcp.Description("Prefix of task ID references such as KB-123, defaults to KB (optional)"),
+		),
+		mcp.WithString("commit_url",
+			mcp.Description("Commit URL template with a {hash} placeholder for the external links, derived from the origin remote by default (optional)"),
+		),
+		mcp.WithString("user",
+			mcp.Description("User to comment as (ID, username or 'me'), defaults to the commit author when they have a Kanboard account (optional)"),
+		),
+		mcp.WithBoolean("close",
+			mcp.Description("Close tasks referenced with fixes/closes/resolves, defaults to false (optional)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only report what would be done (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.scanGitCommitsHandler)
+
+	tool = mcp.NewTool("get_webhook_events",
+		mcp.WithDescription("List the latest events received from Kanboard webhooks (HTTP mode), oldest first"),
+		mcp.WithString("event_name",
+			mcp.Description("Only events whose name starts with this, e.g. 'task.move' or 'comment.' (optional)"),
+		),
+		mcp.WithNumber("project_id",
+			mcp.Description("Only events of this project (optional)"),
+		),
+		mcp.WithNumber("task_id",
+			mcp.Description("Only events of this task (optional)"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only events received after this date, e.g. '2024-05-20 14:00' or 'today' (optional)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of events, defaults to 50 (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.getWebhookEventsHandler)
+
+	tool = mcp.NewTool("standup_digest",
+		mcp.WithDescription("Daily standup digest in Markdown: done since the last working day, in progress, blocked, overdue and due soon, for the current user, a named user or a whole project team. Other users and teams are seen through project activity, of which Kanboard only returns the latest events (about 50), so the digest warns where the history starts when it doesn't reach back far enough"),
+		mcp.WithString("user",
+			mcp.Description("User ID, username, name or 'me'; defaults to 'me', or to the whole team when project_id is set (optional)"),
+		),
+		mcp.WithNumber("project_id",
+			mcp.Description("Only look at this project; without user, gives one digest per team member (optional)"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Start of the 'done' window, e.g. 'yesterday 9am' or '2024-05-20'; defaults to the start of the previous working day (optional)"),
+		),
+		mcp.WithNumber("due_days",
+			mcp.Description("Tasks due within this many days count as due soon, defaults to 3 (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("markdown", "json"),
+			mcp.Description("Output format, defaults to markdown (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.standupDigestHandler)
+
+	tool = mcp.NewTool("release_notes",
+		mcp.WithDescription("Generate a Markdown changelog from the tasks of a project closed in a date range, or carrying given tags or metadata, grouped by category or tag into configurable sections, with their external links (e.g. pull requests)"),
+		mcp.WithNumber("project_id",
+			mcp.Required(),
+			mcp.Description("ID of the project"),
+		),
+		mcp.WithString("from",
+			mcp.Description("Only tasks closed on or after this date; defaults to 14 days ago unless tags or metadata select the tasks (optional)"),
+		),
+		mcp.WithString("to",
+			mcp.Description("Only tasks closed on or before this date, defaults to now (optional)"),
+		),
+		mcp.WithArray("tags",
+			mcp.WithStringItems(),
+			mcp.Description("Only tasks with any of these tags, e.g. ['v2.3'] (optional)"),
+		),
+		mcp.WithArray("metadata",
+			mcp.WithStringItems(),
+			mcp.Description("Only tasks with these metadata values, in key=value form (optional)"),
+		),
+		mcp.WithString("group_by",
+			mcp.Enum("category", "tag"),
+			mcp.Description("Group tasks by category (default) or tag (optional)"),
+		),
+		mcp.WithArray("sections",
+			mcp.WithStringItems(),
+			mcp.Description("Sections in order, as 'Heading: name, name' where names are categories or tags, e.g. ['Features: feature, enhancement', 'Bug fixes: bug', 'Other: *'] (optional)"),
+		),
+		mcp.WithString("other_heading",
+			mcp.Description("Heading for tasks no section takes, defaults to 'Other changes' (optional)"),
+		),
+		mcp.WithString("version",
+			mcp.Description("Version shown in the changelog heading (optional)"),
+		),
+		mcp.WithBoolean("include_links",
+			mcp.Description("List the external links of each task, defaults to true (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("markdown", "json"),
+			mcp.Description("Output format, defaults to markdown (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.releaseNotesHandler)
+
+	tool = mcp.NewTool("lint_project",
+		mcp.WithDescription("Check a project's board hygiene: unassigned, stale or uncategorized tasks, overdue tasks left open in the last column, invalid priorities, closed tasks with open subtasks, finished tasks not moved and columns over their WIP limit. Reports violations by severity with suggested fixes, and can apply the safe ones"),
+		mcp.WithNumber("project_id",
+			mcp.Required(),
+			mcp.Description("ID of the project to lint"),
+		),
+		mcp.WithArray("rules",
+			mcp.WithStringEnumItems(lintRuleIDs()),
+			mcp.Description("Rules to run, defaults to all (optional)"),
+		),
+		mcp.WithArray("severity",
+			mcp.WithStringItems(),
+			mcp.Description("Severity overrides in rule=severity form, e.g. ['unassigned=error'] (optional)"),
+		),
+		mcp.WithString("min_severity",
+			mcp.Enum("info", "warning", "error"),
+			mcp.Description("Only report violations at least this severe, defaults to info (optional)"),
+		),
+		mcp.WithNumber("stale_days",
+			mcp.Description("Days without changes after which an open task is stale, and how far back closed tasks are checked for open subtasks, defaults to 90 (optional)"),
+		),
+		mcp.WithBoolean("fix",
+			mcp.Description("Apply the safe fixes: clamp invalid priorities (optional)"),
+		),
+		mcp.WithBoolean("fix_closed_subtasks",
+			mcp.Description("With fix, also mark the open subtasks of recently closed tasks as done (optional)"),
+		),
+		mcp.WithBoolean("fix_overdue_in_done",
+			mcp.Description("With fix, also close the overdue tasks sitting in the last column (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("markdown", "json"),
+			mcp.Description("Output format, defaults to markdown (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.lintProjectHandler)
+
+	tool = mcp.NewTool("find_duplicates",
+		mcp.WithDescription("Find clusters of likely duplicate open tasks within or across projects, comparing normalized titles, description similarity, references and external links"),
+		mcp.WithArray("project_ids",
+			mcp.WithNumberItems(),
+			mcp.Description("Projects to compare tasks across, defaults to all visible projects (optional)"),
+		),
+		mcp.WithNumber("threshold",
+			mcp.Description("Similarity from 0 to 1 from which tasks count as duplicates, defaults to 0.75 (optional)"),
+		),
+		mcp.WithBoolean("compare_links",
+			mcp.Description("Also compare external links, one API call per task; defaults to true (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Enum("markdown", "json"),
+			mcp.Description("Output format, defaults to markdown (optional)"),
+		),
+	)
+	s.AddTool(tool, kbClient.findDuplicatesHandler)
+
+	if kbClient.events != nil {
+		s.AddResource(mcp.NewResource(webhookEventsURI, "Kanboard events",
+			mcp.WithResourceDescription("The latest task and project events received from Kanboard webhooks, read on demand (no update notifications)"),
+			mcp.WithMIMEType("application/json"),
+		), kbClient.webhookEventsResource)
+	}
+
+	// Serve over HTTP (with the webhook endpoint) when an address is configured
+	if httpAddr != "" {
+		if err := serveHTTP(s, kbClient, httpAddr, os.Getenv("KANBOARD_MCP_HTTP_TOKEN"), os.Getenv("KANBOARD_WEBHOOK_TOKEN")); err != nil {
+			fmt.Printf("Server error: %v\n", err)
+		}
+		return
+	}
+
+	// Start the stdio server
+	if err := server.ServeStdio(s); err != nil {
+		fmt.Printf("Server error: %v\n", err)
+	}
+}
+
+type kanboardClient struct {
+	apiEndpoint string
+	apiKey      string
+	username    string
+	password    string
+	cache       *apiCache
+	events      *eventLog
+}
+
+func newKanboardClient(apiEndpoint, apiKey, username, password string) *kanboardClient {
+	return &kanboardClient{
+		apiEndpoint: apiEndpoint,
+		apiKey:      apiKey,
+		username:    username,
+		password:    password,
+		cache:       newAPICache(lookupCacheTTL),
+	}
+}
+
+// APIResponse represents the standard Kanboard JSON-RPC response structure
+type APIResponse struct {
+	Jsonrpc string      `json:"jsonrpc"`
+	ID      int         `json:"id"`
+	Result  interface{} `json:"result"`
+	Error   *APIError   `json:"error"`
+}
+
+// APIError represents a Kanboard API error response
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// RequestConfig holds configuration for API requests
+type RequestConfig struct {
+	MaxRetries    int
+	RetryDelay    time.Duration
+	Timeout       time.Duration
+	EnableLogging bool
+}
+
+// DefaultRequestConfig returns default configuration for API requests
+func DefaultRequestConfig() *RequestConfig {
+	return &RequestConfig{
+		MaxRetries:    3,
+		RetryDelay:    time.Second * 2,
+		Timeout:       time.Second * 30,
+		EnableLogging: true,
+	}
+}
+
+func (kc *kanboardClient) callKanboardAPI(ctx context.Context, method string, params interface{}) (interface{}, error) {
+	return kc.callKanboardAPIWithConfig(ctx, method, params, DefaultRequestConfig())
+}
+
+func (kc *kanboardClient) callKanboardAPIWithConfig(ctx context.Context, method string, params interface{}, config *RequestConfig) (interface{}, error) {
+	if config == nil {
+		config = DefaultRequestConfig()
+	}
+
+	// Create HTTP client with timeout
+	client := &http.Client{
+		Timeout: config.Timeout,
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if config.EnableLogging {
+				fmt.Printf("Retrying API call to %s (attempt %d/%d)\n", method, attempt+1, config.MaxRetries+1)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(config.RetryDelay):
+			}
+		}
+
+		result, err := kc.executeAPIRequest(ctx, client, method, params, config)
+		if err == nil {
+			return result, nil
+		}
+
+		lastErr = err
+		
+		// Don't retry on authentication or validation errors
+		if isNonRetryableError(err) {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("API call failed after %d attempts: %w", config.MaxRetries+1, lastErr)
+}
+
+func (kc *kanboardClient) executeAPIRequest(ctx context.Context, client *http.Client, method string, params interface{}, config *RequestConfig) (interface{}, error) {
+	// Validate inputs
+	if method == "" {
+		return nil, fmt.Errorf("method cannot be empty")
+	}
+
+	// Prepare request body
+	requestBody := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"id":      generateRequestID(),
+		"params":  params,
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", kc.apiEndpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "KanboardMCP/1.0")
@@ -2419,18 +3095,13 @@ func isNonRetryableError(err error) bool {
 	return false
 }
 
-func (kc *kanboardClient) getProjectsHandler(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (kc *kanboardClient) getProjectsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	result, err := kc.callKanboardAPI(ctx, "getAllProjects", nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatListResult(ctx, request, result, "project")
 }
 
 func (kc *kanboardClient) createProjectHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -2456,14 +3127,20 @@ func (kc *kanboardClient) createProjectHandler(ctx context.Context, request mcp.
 		params["identifier"] = identifier
 	}
 
-	startDate := request.GetString("start_date", "")
-	if startDate != "" {
-		params["start_date"] = startDate
+	if startDate := request.GetString("start_date", ""); startDate != "" {
+		date, err := kc.kanboardDate(ctx, startDate, true, nil)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("start_date: %v", err)), nil
+		}
+		params["start_date"] = date
 	}
 
-	endDate := request.GetString("end_date", "")
-	if endDate != "" {
-		params["end_date"] = endDate
+	if endDate := request.GetString("end_date", ""); endDate != "" {
+		date, err := kc.kanboardDate(ctx, endDate, true, nil)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("end_date: %v", err)), nil
+		}
+		params["end_date"] = date
 	}
 
 	priorityDefault := request.GetInt("priority_default", 0)
@@ -2575,12 +3252,7 @@ func (kc *kanboardClient) getTasksHandler(ctx context.Context, request mcp.CallT
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get tasks: %v", err)), nil
 	}
 
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatListResult(ctx, request, result, "task")
 }
 
 func (kc *kanboardClient) createTaskHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -2682,9 +3354,12 @@ func (kc *kanboardClient) createTaskHandler(ctx context.Context, request mcp.Cal
 		params["creator_id"] = creatorId
 	}
 
-	dateDue := request.GetString("date_due", "")
-	if dateDue != "" {
-		params["date_due"] = dateDue
+	if dateDue := request.GetString("date_due", ""); dateDue != "" {
+		date, err := kc.kanboardDate(ctx, dateDue, false, func() (int, error) { return strconv.Atoi(projectID) })
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("date_due: %v", err)), nil
+		}
+		params["date_due"] = date
 	}
 
 	description := request.GetString("description", "")
@@ -2747,15 +3422,39 @@ func (kc *kanboardClient) createTaskHandler(ctx context.Context, request mcp.Cal
 		params["tags"] = tags
 	}
 
-	dateStarted := request.GetString("date_started", "")
-	if dateStarted != "" {
-		params["date_started"] = dateStarted
+	if dateStarted := request.GetString("date_started", ""); dateStarted != "" {
+		date, err := kc.kanboardDate(ctx, dateStarted, false, func() (int, error) { return strconv.Atoi(projectID) })
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("date_started: %v", err)), nil
+		}
+		params["date_started"] = date
+	}
+
+	// Look for similar open tasks of the project before adding another one.
+	var similar []map[string]interface{}
+	switch dedupe := request.GetString("dedupe", "off"); dedupe {
+	case "off":
+	case "warn", "refuse":
+		projectIDInt, _ := strconv.Atoi(projectID)
+		similar, err = kc.similarOpenTasks(ctx, projectIDInt, newDuplicateText(title, description, reference, nil), duplicateThreshold)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if dedupe == "refuse" && len(similar) > 0 {
+			similarBytes, _ := json.MarshalIndent(similar, "", "  ")
+			return mcp.NewToolResultError(fmt.Sprintf("Task not created: similar open tasks already exist in '%s':\n%s\nUpdate one of them, or set dedupe to off to create the task anyway", projectName, similarBytes)), nil
+		}
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported dedupe '%s' (expected off, warn or refuse)", dedupe)), nil
 	}
 
 	result, err = kc.callKanboardAPI(ctx, "createTask", params)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create task: %v", err)), nil
 	}
+	if len(similar) > 0 {
+		result = map[string]interface{}{"task_id": result, "possible_duplicates": similar}
+	}
 
 	resultBytes, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
@@ -2773,84 +3472,31 @@ func (kc *kanboardClient) updateTaskHandler(ctx context.Context, request mcp.Cal
 
 	params := map[string]interface{}{"id": id}
 
-	title := request.GetString("title", "")
-	if title != "" {
-		params["title"] = title
-	}
-
-	colorId := request.GetString("color_id", "")
-	if colorId != "" {
-		params["color_id"] = colorId
-	}
-
-	ownerId := request.GetInt("owner_id", 0)
-	if ownerId != 0 {
-		params["owner_id"] = ownerId
-	}
-
-	dateDue := request.GetString("date_due", "")
-	if dateDue != "" {
-		params["date_due"] = dateDue
-	}
-
-	description := request.GetString("description", "")
-	if description != "" {
-		params["description"] = description
-	}
-
-	categoryId := request.GetInt("category_id", 0)
-	if categoryId != 0 {
-		params["category_id"] = categoryId
-	}
-
-	score := request.GetInt("score", 0)
-	if score != 0 {
-		params["score"] = score
-	}
-
-	priority := request.GetInt("priority", 0)
-	if priority != 0 {
-		params["priority"] = priority
-	}
-
-	recurrenceStatus := request.GetInt("recurrence_status", 0)
-	if recurrenceStatus != 0 {
-		params["recurrence_status"] = recurrenceStatus
-	}
-
-	recurrenceTrigger := request.GetInt("recurrence_trigger", 0)
-	if recurrenceTrigger != 0 {
-		params["recurrence_trigger"] = recurrenceTrigger
-	}
-
-	recurrenceFactor := request.GetInt("recurrence_factor", 0)
-	if recurrenceFactor != 0 {
-		params["recurrence_factor"] = recurrenceFactor
-	}
-
-	recurrenceTimeframe := request.GetInt("recurrence_timeframe", 0)
-	if recurrenceTimeframe != 0 {
-		params["recurrence_timeframe"] = recurrenceTimeframe
-	}
-
-	recurrenceBasedate := request.GetInt("recurrence_basedate", 0)
-	if recurrenceBasedate != 0 {
-		params["recurrence_basedate"] = recurrenceBasedate
-	}
-
-	reference := request.GetString("reference", "")
-	if reference != "" {
-		params["reference"] = reference
-	}
-
-	tags := request.GetStringSlice("tags", []string{})
-	if len(tags) > 0 {
-		params["tags"] = tags
-	}
-
-	dateStarted := request.GetString("date_started", "")
-	if dateStarted != "" {
-		params["date_started"] = dateStarted
+	update := newPartialUpdate(request, params)
+	update.stringField("title", "title")
+	update.stringField("color_id", "color_id")
+	update.intField("owner_id", "owner_id")
+	update.stringField("description", "description")
+	update.intField("category_id", "category_id")
+	update.intField("score", "score")
+	update.intField("priority", "priority")
+	update.intField("recurrence_status", "recurrence_status")
+	update.intField("recurrence_trigger", "recurrence_trigger")
+	update.intField("recurrence_factor", "recurrence_factor")
+	update.intField("recurrence_timeframe", "recurrence_timeframe")
+	update.intField("recurrence_basedate", "recurrence_basedate")
+	update.stringField("reference", "reference")
+	update.stringsField("tags", "tags")
+	parseDate := func(value string) (string, error) {
+		return kc.kanboardDate(ctx, value, false, kc.taskProject(ctx, id))
+	}
+	for _, field := range []string{"date_due", "date_started"} {
+		if err := update.dateField(field, field, parseDate); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	result, err := kc.callKanboardAPI(ctx, "updateTask", params)
@@ -2926,6 +3572,12 @@ func (kc *kanboardClient) moveTaskPositionHandler(ctx context.Context, request m
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	if !request.GetBool("override_wip_limit", false) {
+		if err := kc.checkWIPLimit(ctx, projectId, taskId, columnId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
 	params := map[string]interface{}{
 		"project_id":  projectId,
 		"task_id":     taskId,
@@ -2947,18 +3599,13 @@ func (kc *kanboardClient) moveTaskPositionHandler(ctx context.Context, request m
 	return mcp.NewToolResultText(string(resultBytes)), nil
 }
 
-func (kc *kanboardClient) getUsersHandler(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (kc *kanboardClient) getUsersHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	result, err := kc.callKanboardAPI(ctx, "getAllUsers", nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatPagedListResult(ctx, request, result, "user")
 }
 
 func (kc *kanboardClient) getUserByNameHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -3031,24 +3678,13 @@ func (kc *kanboardClient) updateUserHandler(ctx context.Context, request mcp.Cal
 
 	params := map[string]interface{}{"id": id}
 
-	username := request.GetString("username", "")
-	if username != "" {
-		params["username"] = username
-	}
-
-	name := request.GetString("name", "")
-	if name != "" {
-		params["name"] = name
-	}
-
-	email := request.GetString("email", "")
-	if email != "" {
-		params["email"] = email
-	}
-
-	role := request.GetString("role", "")
-	if role != "" {
-		params["role"] = role
+	update := newPartialUpdate(request, params)
+	update.stringField("username", "username")
+	update.stringField("name", "name")
+	update.stringField("email", "email")
+	update.stringField("role", "role")
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	result, err := kc.callKanboardAPI(ctx, "updateUser", params)
@@ -3152,16 +3788,12 @@ func (kc *kanboardClient) getMyProjectsListHandler(ctx context.Context, _ mcp.Ca
 	return mcp.NewToolResultText(string(resultBytes)), nil
 }
 
-func (kc *kanboardClient) getMyOverdueTasksHandler(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (kc *kanboardClient) getMyOverdueTasksHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	result, err := kc.callKanboardAPI(ctx, "getMyOverdueTasks", nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatListResult(ctx, request, result, "task")
 }
 
 func (kc *kanboardClient) getMyProjectsHandler(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -3258,17 +3890,12 @@ func (kc *kanboardClient) updateExternalTaskLinkHandler(ctx context.Context, req
 
 	params := map[string]interface{}{"task_id": taskId, "link_id": linkId}
 
-	title := request.GetString("title", "")
-	if title != "" {
-		params["title"] = title
-	}
-	url := request.GetString("url", "")
-	if url != "" {
-		params["url"] = url
-	}
-	dependency := request.GetString("dependency", "")
-	if dependency != "" {
-		params["dependency"] = dependency
+	update := newPartialUpdate(request, params)
+	update.stringField("title", "title")
+	update.stringField("url", "url")
+	update.stringField("dependency", "dependency")
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	result, err := kc.callKanboardAPI(ctx, "updateExternalTaskLink", params)
@@ -3439,14 +4066,11 @@ func (kc *kanboardClient) updateColumnHandler(ctx context.Context, request mcp.C
 
 	params := map[string]interface{}{"id": columnId, "title": title}
 
-	taskLimit := request.GetInt("task_limit", 0)
-	if taskLimit != 0 {
-		params["task_limit"] = taskLimit
-	}
-
-	description := request.GetString("description", "")
-	if description != "" {
-		params["description"] = description
+	update := newPartialUpdate(request, params)
+	update.intField("task_limit", "task_limit")
+	update.stringField("description", "description")
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	result, err := kc.callKanboardAPI(ctx, "updateColumn", params)
@@ -3602,14 +4226,11 @@ func (kc *kanboardClient) updateCategoryHandler(ctx context.Context, request mcp
 	}
 
 	params := map[string]interface{}{"id": categoryId}
-	name := request.GetString("name", "")
-	if name != "" {
-		params["name"] = name
-	}
-
-	colorId := request.GetString("color_id", "")
-	if colorId != "" {
-		params["color_id"] = colorId
+	update := newPartialUpdate(request, params)
+	update.stringField("name", "name")
+	update.stringField("color_id", "color_id")
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	result, err := kc.callKanboardAPI(ctx, "updateCategory", params)
@@ -3722,7 +4343,12 @@ func (kc *kanboardClient) setTaskDueDateHandler(ctx context.Context, request mcp
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	params := map[string]interface{}{"task_id": taskId, "date_due": dueDate}
+	dateDue, err := kc.kanboardDate(ctx, dueDate, false, kc.taskProject(ctx, taskId))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("due_date: %v", err)), nil
+	}
+
+	params := map[string]interface{}{"task_id": taskId, "date_due": dateDue}
 	result, err := kc.callKanboardAPI(ctx, "updateTask", params)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to set task due date: %v", err)), nil
@@ -3802,12 +4428,7 @@ func (kc *kanboardClient) getTaskCommentsHandler(ctx context.Context, request mc
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get task comments: %v", err)), nil
 	}
 
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatListResult(ctx, request, result, "comment")
 }
 
 func (kc *kanboardClient) getCommentHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -3955,13 +4576,11 @@ func (kc *kanboardClient) updateGroupHandler(ctx context.Context, request mcp.Ca
 	}
 
 	params := map[string]interface{}{"id": groupId}
-	name := request.GetString("name", "")
-	if name != "" {
-		params["name"] = name
-	}
-	externalId := request.GetString("external_id", "")
-	if externalId != "" {
-		params["external_id"] = externalId
+	update := newPartialUpdate(request, params)
+	update.stringField("name", "name")
+	update.stringField("external_id", "external_id")
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	result, err := kc.callKanboardAPI(ctx, "updateGroup", params)
@@ -4434,16 +5053,12 @@ func (kc *kanboardClient) getProjectByEmailHandler(ctx context.Context, request
 	return mcp.NewToolResultText(string(resultBytes)), nil
 }
 
-func (kc *kanboardClient) getAllProjectsHandler(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (kc *kanboardClient) getAllProjectsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	result, err := kc.callKanboardAPI(ctx, "getAllProjects", nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatPagedListResult(ctx, request, result, "project")
 }
 
 func (kc *kanboardClient) updateProjectHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -4454,54 +5069,25 @@ func (kc *kanboardClient) updateProjectHandler(ctx context.Context, request mcp.
 
 	params := map[string]interface{}{"id": projectId}
 
-	name := request.GetString("name", "")
-	if name != "" {
-		params["name"] = name
-	}
-
-	description := request.GetString("description", "")
-	if description != "" {
-		params["description"] = description
-	}
-
-	ownerId := request.GetInt("owner_id", 0)
-	if ownerId != 0 {
-		params["owner_id"] = ownerId
-	}
-
-	identifier := request.GetString("identifier", "")
-	if identifier != "" {
-		params["identifier"] = identifier
-	}
-
-	startDate := request.GetString("start_date", "")
-	if startDate != "" {
-		params["start_date"] = startDate
-	}
-
-	endDate := request.GetString("end_date", "")
-	if endDate != "" {
-		params["end_date"] = endDate
-	}
-
-	priorityDefault := request.GetInt("priority_default", 0)
-	if priorityDefault != 0 {
-		params["priority_default"] = priorityDefault
-	}
-
-	priorityStart := request.GetInt("priority_start", 0)
-	if priorityStart != 0 {
-		params["priority_start"] = priorityStart
-	}
-
-	priorityEnd := request.GetInt("priority_end", 0)
-	if priorityEnd != 0 {
-		params["priority_end"] = priorityEnd
+	update := newPartialUpdate(request, params)
+	update.stringField("name", "name")
+	update.stringField("description", "description")
+	update.intField("owner_id", "owner_id")
+	update.stringField("identifier", "identifier")
+	update.intField("priority_default", "priority_default")
+	update.intField("priority_start", "priority_start")
+	update.intField("priority_end", "priority_end")
+	update.stringField("email", "email")
+	parseDate := func(value string) (string, error) {
+		return kc.kanboardDate(ctx, value, true, nil)
+	}
+	for _, field := range []string{"start_date", "end_date"} {
+		if err := update.dateField(field, field, parseDate); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 	}
-
-	email := request.GetString("email", "")
-	if email != "" {
-		params["email"] = email
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	result, err := kc.callKanboardAPI(ctx, "updateProject", params)
@@ -4612,11 +5198,7 @@ func (kc *kanboardClient) getProjectActivityHandler(ctx context.Context, request
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get project activity: %v", err)), nil
 	}
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatListResult(ctx, request, result, "activity")
 }
 
 func (kc *kanboardClient) getProjectActivitiesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -4629,11 +5211,7 @@ func (kc *kanboardClient) getProjectActivitiesHandler(ctx context.Context, reque
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get project activities: %v", err)), nil
 	}
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatPagedListResult(ctx, request, result, "activity")
 }
 
 func (kc *kanboardClient) createProjectFileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -5116,11 +5694,7 @@ func (kc *kanboardClient) getAllSubtasksHandler(ctx context.Context, request mcp
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatListResult(ctx, request, result, "subtask")
 }
 
 func (kc *kanboardClient) updateSubtaskHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -5134,25 +5708,14 @@ func (kc *kanboardClient) updateSubtaskHandler(ctx context.Context, request mcp.
 	}
 	params := map[string]interface{}{"id": id, "task_id": taskId}
 
-	title := request.GetString("title", "")
-	if title != "" {
-		params["title"] = title
-	}
-	userId := request.GetInt("user_id", 0)
-	if userId != 0 {
-		params["user_id"] = userId
-	}
-	timeEstimated := request.GetInt("time_estimated", 0)
-	if timeEstimated != 0 {
-		params["time_estimated"] = timeEstimated
-	}
-	timeSpent := request.GetInt("time_spent", 0)
-	if timeSpent != 0 {
-		params["time_spent"] = timeSpent
-	}
-	status := request.GetInt("status", 0)
-	if status != 0 {
-		params["status"] = status
+	update := newPartialUpdate(request, params)
+	update.stringField("title", "title")
+	update.intField("user_id", "user_id")
+	update.floatField("time_estimated", "time_estimated")
+	update.floatField("time_spent", "time_spent")
+	update.intField("status", "status")
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	result, err := kc.callKanboardAPI(ctx, "updateSubtask", params)
@@ -5331,9 +5894,10 @@ func (kc *kanboardClient) updateTagHandler(ctx context.Context, request mcp.Call
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 	params := map[string]interface{}{"id": tagId, "name": tag}
-	colorId := request.GetInt("color_id", 0)
-	if colorId != 0 {
-		params["color_id"] = colorId
+	update := newPartialUpdate(request, params)
+	update.intField("color_id", "color_id")
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 	result, err := kc.callKanboardAPI(ctx, "updateTag", params)
 	if err != nil {
@@ -5985,14 +6549,22 @@ func (kc *kanboardClient) ChangeSwimlanePosition(projectID, swimlaneID, position
 	return false, fmt.Errorf("unexpected result type for ChangeSwimlanePosition: %T", result)
 }
 
-func (kc *kanboardClient) UpdateSwimlane(projectID, swimlaneID int, name, description string) (bool, error) {
+// UpdateSwimlane updates the given swimlane fields. Kanboard requires a name, so the
+// current one is kept when values doesn't set it.
+func (kc *kanboardClient) UpdateSwimlane(projectID, swimlaneID int, values map[string]interface{}) (bool, error) {
 	params := map[string]interface{}{
 		"project_id": projectID,
 		"id":         swimlaneID,
-		"name":       name,
 	}
-	if description != "" {
-		params["description"] = description
+	for key, value := range values {
+		params[key] = value
+	}
+	if _, ok := params["name"]; !ok {
+		current, err := kc.GetSwimlaneById(swimlaneID)
+		if err != nil {
+			return false, err
+		}
+		params["name"] = asString(asMap(current)["name"])
 	}
 	result, err := kc.callKanboardAPI(context.Background(), "updateSwimlane", params)
 	if err != nil {
@@ -6197,9 +6769,14 @@ func (kc *kanboardClient) updateSwimlaneHandler(_ context.Context, request mcp.C
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	name := request.GetString("name", "")
-	description := request.GetString("description", "")
-	result, err := kc.UpdateSwimlane(projectId, swimlaneId, name, description)
+	params := map[string]interface{}{}
+	update := newPartialUpdate(request, params)
+	update.stringField("name", "name")
+	update.stringField("description", "description")
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := kc.UpdateSwimlane(projectId, swimlaneId, params)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -6431,23 +7008,15 @@ func (kc *kanboardClient) getAllTasksHandler(ctx context.Context, request mcp.Ca
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get tasks: %v", err)), nil
 	}
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatPagedListResult(ctx, request, result, "task")
 }
 
-func (kc *kanboardClient) getOverdueTasksHandler(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (kc *kanboardClient) getOverdueTasksHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	result, err := kc.callKanboardAPI(ctx, "getOverdueTasks", nil)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get overdue tasks: %v", err)), nil
 	}
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatListResult(ctx, request, result, "task")
 }
 
 func (kc *kanboardClient) getOverdueTasksByProjectHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -6460,11 +7029,7 @@ func (kc *kanboardClient) getOverdueTasksByProjectHandler(ctx context.Context, r
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get overdue tasks: %v", err)), nil
 	}
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatListResult(ctx, request, result, "task")
 }
 
 func (kc *kanboardClient) openTaskHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -6609,12 +7174,7 @@ func (kc *kanboardClient) searchTasksHandler(ctx context.Context, request mcp.Ca
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to search tasks: %v", err)), nil
 	}
 
-	resultBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return kc.formatPagedListResult(ctx, request, result, "task")
 }
 
 func (kc *kanboardClient) createLdapUserHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -6784,6 +7344,15 @@ func (kc *kanboardClient) createSprintHandler(ctx context.Context, request mcp.C
 	if endDate == "" {
 		return mcp.NewToolResultError("End date is required"), nil
 	}
+	projectOf := func() (int, error) { return projectID, nil }
+	startDate, err := kc.kanboardDate(ctx, startDate, true, projectOf)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("start_date: %v", err)), nil
+	}
+	endDate, err = kc.kanboardDate(ctx, endDate, true, projectOf)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("end_date: %v", err)), nil
+	}
 
 	params := map[string]interface{}{
 		"project_id": projectID,
@@ -6833,22 +7402,27 @@ func (kc *kanboardClient) updateSprintHandler(ctx context.Context, request mcp.C
 	params := make(map[string]interface{})
 	params["sprint_id"] = sprintID
 
-	if name := request.GetString("name", ""); name != "" {
-		params["name"] = name
-	}
-	if startDate := request.GetString("start_date", ""); startDate != "" {
-		params["start_date"] = startDate
+	update := newPartialUpdate(request, params)
+	update.stringField("name", "name")
+	update.stringField("sprint_goal", "goal")
+	update.boolField("is_completed", "is_completed")
+	update.boolField("is_active", "is_active")
+	projectOf := func() (int, error) {
+		sprint, err := kc.getSprint(ctx, sprintID)
+		return sprint.ProjectID, err
+	}
+	parseDate := func(value string) (string, error) {
+		return kc.kanboardDate(ctx, value, true, projectOf)
+	}
+	for _, field := range []string{"start_date", "end_date"} {
+		if err := update.dateField(field, field, parseDate); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 	}
-	if endDate := request.GetString("end_date", ""); endDate != "" {
-		params["end_date"] = endDate
+	if err := update.unknownCleared(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	isCompleted := request.GetBool("is_completed", false)
-	isActive := request.GetBool("is_active", false)
-
-	params["is_completed"] = isCompleted
-	params["is_active"] = isActive
-
 	result, err := kc.callKanboardAPI(ctx, "updateSprint", params)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to update sprint: %v", err)), nil
@@ -6952,3 +7526,7548 @@ func (kc *kanboardClient) getAllSprintsByProjectHandler(ctx context.Context, req
 	return mcp.NewToolResultText(string(resultBytes)), nil
 }
 
+// listFormats are the output formats accepted by the "format" argument of list tools.
+var listFormats = []string{"json", "compact", "markdown_table", "summary"}
+
+// timestampFields lists the Kanboard fields that hold unix timestamps.
+var timestampFields = map[string]bool{
+	"date_creation":     true,
+	"date_modification": true,
+	"date_due":          true,
+	"date_completed":    true,
+	"date_started":      true,
+	"date_moved":        true,
+	"last_modified":     true,
+	"date_last_login":   true,
+}
+
+// defaultListFields are the fields shown by the non-JSON formats when no projection is given.
+var defaultListFields = map[string][]string{
+	"task":     {"id", "title", "project", "column", "swimlane", "assignee", "category", "priority", "score", "date_due", "date_modification", "is_active"},
+	"project":  {"id", "name", "identifier", "is_active", "is_public", "owner", "last_modified"},
+	"user":     {"id", "username", "name", "email", "role", "is_active"},
+	"subtask":  {"id", "task_id", "title", "status_name", "assignee", "time_estimated", "time_spent"},
+	"comment":  {"id", "task_id", "author", "date_creation", "comment"},
+	"activity": {"id", "task_id", "event_name", "author", "date_creation", "event_title"},
+	"search":   {"rank", "id", "title", "project", "column", "assignee", "date_due", "is_active", "matched_in"},
+}
+
+// withListFormat adds the "format" and "fields" arguments shared by list tools.
+func withListFormat() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithString("format",
+			mcp.Enum(listFormats...),
+			mcp.Description("Output format: json (raw payload, default), compact (one-line JSON with names and ISO dates), markdown_table or summary (optional)"),
+		)(t)
+		mcp.WithArray("fields",
+			mcp.WithStringItems(),
+			mcp.Description("Only return these fields, e.g. [\"id\", \"title\", \"assignee\", \"date_due\"] (optional)"),
+		)(t)
+	}
+}
+
+// formatListResult renders a list payload according to the "format" and "fields" arguments.
+func (kc *kanboardClient) formatListResult(ctx context.Context, request mcp.CallToolRequest, result interface{}, kind string) (*mcp.CallToolResult, error) {
+	format := request.GetString("format", "json")
+	fields := request.GetStringSlice("fields", nil)
+
+	text, err := kc.renderList(ctx, result, kind, format, fields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+func (kc *kanboardClient) renderList(ctx context.Context, result interface{}, kind, format string, fields []string) (string, error) {
+	items, isList := result.([]interface{})
+	if isList && items == nil {
+		// Client-side filters that match nothing leave a nil list, which must render as [].
+		items, result = []interface{}{}, []interface{}{}
+	}
+
+	if format == "" || format == "json" {
+		if !isList || len(fields) == 0 {
+			resultBytes, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return "", fmt.Errorf("Failed to marshal API result: %v", err)
+			}
+			return string(resultBytes), nil
+		}
+		projected := make([]map[string]interface{}, 0, len(items))
+		for _, item := range items {
+			if m, ok := item.(map[string]interface{}); ok {
+				projected = append(projected, projectFields(m, fields))
+			}
+		}
+		resultBytes, err := json.MarshalIndent(projected, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("Failed to marshal API result: %v", err)
+		}
+		return string(resultBytes), nil
+	}
+
+	if len(fields) == 0 {
+		fields = defaultListFields[kind]
+	}
+
+	resolver := kc.newNameResolver(ctx)
+	rows := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		normalized := resolver.normalize(ctx, m)
+		if len(fields) > 0 {
+			normalized = projectFields(normalized, fields)
+		}
+		rows = append(rows, normalized)
+	}
+
+	switch format {
+	case "compact":
+		for _, row := range rows {
+			for key, value := range row {
+				if value == nil || value == "" {
+					delete(row, key)
+				}
+			}
+		}
+		resultBytes, err := json.Marshal(rows)
+		if err != nil {
+			return "", fmt.Errorf("Failed to marshal API result: %v", err)
+		}
+		return string(resultBytes), nil
+	case "markdown_table":
+		return renderMarkdownTable(rows, fields), nil
+	case "summary":
+		return renderSummary(rows, kind), nil
+	default:
+		return "", fmt.Errorf("unsupported format '%s' (expected one of: %s)", format, strings.Join(listFormats, ", "))
+	}
+}
+
+// projectFields returns a copy of item that only contains the requested fields.
+func projectFields(item map[string]interface{}, fields []string) map[string]interface{} {
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := item[field]; ok {
+			projected[field] = value
+		}
+	}
+	return projected
+}
+
+func renderMarkdownTable(rows []map[string]interface{}, fields []string) string {
+	if len(fields) == 0 {
+		seen := map[string]bool{}
+		for _, row := range rows {
+			for key := range row {
+				if !seen[key] {
+					seen[key] = true
+					fields = append(fields, key)
+				}
+			}
+		}
+		sort.Strings(fields)
+	}
+	if len(rows) == 0 {
+		return "_No results._"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| " + strings.Join(fields, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat("---|", len(fields)) + "\n")
+	for _, row := range rows {
+		cells := make([]string, len(fields))
+		for i, field := range fields {
+			cells[i] = markdownCell(row[field])
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return sb.String()
+}
+
+func markdownCell(value interface{}) string {
+	text := asString(value)
+	text = strings.ReplaceAll(text, "\r", "")
+	text = strings.ReplaceAll(text, "\n", " ")
+	if runes := []rune(text); len(runes) > 120 {
+		text = string(runes[:117]) + "..."
+	}
+	return strings.ReplaceAll(text, "|", "\\|")
+}
+
+func renderSummary(rows []map[string]interface{}, kind string) string {
+	label := kind
+	if kind == "search" {
+		label = "result"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d %s(s)\n", len(rows), label)
+	for _, row := range rows {
+		var parts []string
+		switch kind {
+		case "task", "search":
+			parts = append(parts, fmt.Sprintf("#%s %s", asString(row["id"]), asString(row["title"])))
+			if project := asString(row["project"]); project != "" && kind == "search" {
+				parts = append(parts, "("+project+")")
+			}
+			if column := asString(row["column"]); column != "" {
+				location := column
+				if swimlane := asString(row["swimlane"]); swimlane != "" {
+					location += " / " + swimlane
+				}
+				parts = append(parts, "["+location+"]")
+			}
+			if assignee := asString(row["assignee"]); assignee != "" {
+				parts = append(parts, "@"+assignee)
+			}
+			if due := asString(row["date_due"]); due != "" {
+				parts = append(parts, "due "+due)
+			}
+			if priority := asInt(row["priority"]); priority != 0 {
+				parts = append(parts, fmt.Sprintf("P%d", priority))
+			}
+			if matchedIn := asList(row["matched_in"]); len(matchedIn) > 0 {
+				parts = append(parts, fmt.Sprintf("(matched in %s, rank %s)", joinValues(matchedIn, ", "), asString(row["rank"])))
+			}
+		case "project":
+			parts = append(parts, fmt.Sprintf("#%s %s", asString(row["id"]), asString(row["name"])))
+			if identifier := asString(row["identifier"]); identifier != "" {
+				parts = append(parts, "("+identifier+")")
+			}
+			if asInt(row["is_active"]) == 0 {
+				parts = append(parts, "inactive")
+			}
+		case "user":
+			parts = append(parts, fmt.Sprintf("#%s %s", asString(row["id"]), asString(row["username"])))
+			if name := asString(row["name"]); name != "" {
+				parts = append(parts, "("+name+")")
+			}
+			if role := asString(row["role"]); role != "" {
+				parts = append(parts, role)
+			}
+		case "subtask":
+			parts = append(parts, fmt.Sprintf("#%s %s [%s]", asString(row["id"]), asString(row["title"]), asString(row["status_name"])))
+			if assignee := asString(row["assignee"]); assignee != "" {
+				parts = append(parts, "@"+assignee)
+			}
+			parts = append(parts, fmt.Sprintf("%sh/%sh", asString(row["time_spent"]), asString(row["time_estimated"])))
+		case "comment":
+			comment := strings.SplitN(asString(row["comment"]), "\n", 2)[0]
+			parts = append(parts, fmt.Sprintf("#%s %s @%s: %s", asString(row["id"]), asString(row["date_creation"]), asString(row["author"]), comment))
+		case "activity":
+			parts = append(parts, fmt.Sprintf("%s %s", asString(row["date_creation"]), asString(row["event_title"])))
+		default:
+			parts = append(parts, fmt.Sprintf("#%s", asString(row["id"])))
+		}
+		sb.WriteString("- " + strings.Join(parts, " ") + "\n")
+	}
+	return sb.String()
+}
+
+// nameResolver turns the numeric IDs of a Kanboard payload into readable names.
+// Lookups are fetched lazily through the client's lookup cache.
+type nameResolver struct {
+	kc             *kanboardClient
+	loc            *time.Location
+	users          map[int]string
+	projects       map[int]string
+	columns        map[int]string
+	swimlanes      map[int]string
+	categories     map[int]string
+	loadedProjects map[int]bool
+	usersLoaded    bool
+	projectsLoaded bool
+}
+
+// newNameResolver resolves the instance timezone once, up front, for the timestamps of
+// every item the resolver normalizes.
+func (kc *kanboardClient) newNameResolver(ctx context.Context) *nameResolver {
+	return &nameResolver{
+		kc:             kc,
+		loc:            kc.location(ctx),
+		users:          map[int]string{},
+		projects:       map[int]string{},
+		columns:        map[int]string{},
+		swimlanes:      map[int]string{},
+		categories:     map[int]string{},
+		loadedProjects: map[int]bool{},
+	}
+}
+
+func (r *nameResolver) loadUsers(ctx context.Context) {
+	if r.usersLoaded {
+		return
+	}
+	r.usersLoaded = true
+	result, err := r.kc.callKanboardAPICached(ctx, "getAllUsers", nil)
+	if err != nil {
+		return
+	}
+	for _, item := range asList(result) {
+		user := asMap(item)
+		name := asString(user["name"])
+		if name == "" {
+			name = asString(user["username"])
+		}
+		r.users[asInt(user["id"])] = name
+	}
+}
+
+func (r *nameResolver) loadProjects(ctx context.Context) {
+	if r.projectsLoaded {
+		return
+	}
+	r.projectsLoaded = true
+	result, err := r.kc.callKanboardAPICached(ctx, "getAllProjects", nil)
+	if err != nil {
+		return
+	}
+	for _, item := range asList(result) {
+		project := asMap(item)
+		r.projects[asInt(project["id"])] = asString(project["name"])
+	}
+}
+
+// loadProject fetches the columns, swimlanes and categories of a project.
+// Their IDs are unique across projects, so they share flat lookup maps.
+func (r *nameResolver) loadProject(ctx context.Context, projectID int) {
+	if projectID == 0 || r.loadedProjects[projectID] {
+		return
+	}
+	r.loadedProjects[projectID] = true
+
+	if result, err := r.kc.callKanboardAPICached(ctx, "getColumns", map[string]int{"project_id": projectID}); err == nil {
+		for _, item := range asList(result) {
+			column := asMap(item)
+			r.columns[asInt(column["id"])] = asString(column["title"])
+		}
+	}
+	if result, err := r.kc.callKanboardAPICached(ctx, "getAllSwimlanes", map[string]int{"project_id": projectID}); err == nil {
+		for _, item := range asList(result) {
+			swimlane := asMap(item)
+			r.swimlanes[asInt(swimlane["id"])] = asString(swimlane["name"])
+		}
+	}
+	if result, err := r.kc.callKanboardAPICached(ctx, "getAllCategories", map[string]int{"project_id": projectID}); err == nil {
+		for _, item := range asList(result) {
+			category := asMap(item)
+			r.categories[asInt(category["id"])] = asString(category["name"])
+		}
+	}
+}
+
+func (r *nameResolver) userName(ctx context.Context, userID int) string {
+	if userID == 0 {
+		return ""
+	}
+	r.loadUsers(ctx)
+	return r.users[userID]
+}
+
+func (r *nameResolver) projectName(ctx context.Context, projectID int) string {
+	if projectID == 0 {
+		return ""
+	}
+	r.loadProjects(ctx)
+	return r.projects[projectID]
+}
+
+func (r *nameResolver) columnName(ctx context.Context, projectID, columnID int) string {
+	r.loadProject(ctx, projectID)
+	return r.columns[columnID]
+}
+
+func (r *nameResolver) swimlaneName(ctx context.Context, projectID, swimlaneID int) string {
+	r.loadProject(ctx, projectID)
+	return r.swimlanes[swimlaneID]
+}
+
+func (r *nameResolver) categoryName(ctx context.Context, projectID, categoryID int) string {
+	if categoryID == 0 {
+		return ""
+	}
+	r.loadProject(ctx, projectID)
+	return r.categories[categoryID]
+}
+
+// normalize returns a copy of item with timestamps converted to ISO dates and
+// resolved names added next to the IDs they describe.
+func (r *nameResolver) normalize(ctx context.Context, item map[string]interface{}) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(item)+6)
+	for key, value := range item {
+		if timestampFields[key] {
+			normalized[key] = formatTimestamp(asInt(value), r.loc)
+			continue
+		}
+		normalized[key] = value
+	}
+
+	projectID := asInt(item["project_id"])
+	if _, ok := item["project_name"]; ok {
+		normalized["project"] = item["project_name"]
+	} else if projectID != 0 {
+		normalized["project"] = r.projectName(ctx, projectID)
+	}
+	if _, ok := item["column_id"]; ok {
+		if title := asString(item["column_title"]); title != "" {
+			normalized["column"] = title
+		} else {
+			normalized["column"] = r.columnName(ctx, projectID, asInt(item["column_id"]))
+		}
+	}
+	if _, ok := item["swimlane_id"]; ok {
+		if name := asString(item["swimlane_name"]); name != "" {
+			normalized["swimlane"] = name
+		} else {
+			normalized["swimlane"] = r.swimlaneName(ctx, projectID, asInt(item["swimlane_id"]))
+		}
+	}
+	if _, ok := item["category_id"]; ok {
+		normalized["category"] = r.categoryName(ctx, projectID, asInt(item["category_id"]))
+	}
+	if _, ok := item["owner_id"]; ok {
+		normalized["assignee"] = r.userName(ctx, asInt(item["owner_id"]))
+		normalized["owner"] = normalized["assignee"]
+	} else if name := asString(item["assignee_name"]); name != "" {
+		normalized["assignee"] = name
+	} else if username := asString(item["assignee_username"]); username != "" {
+		normalized["assignee"] = username
+	}
+	if _, ok := item["user_id"]; ok {
+		name := asString(item["name"])
+		if name == "" {
+			name = asString(item["username"])
+		}
+		if name == "" {
+			name = r.userName(ctx, asInt(item["user_id"]))
+		}
+		normalized["assignee"] = name
+		normalized["author"] = name
+	}
+	if author := asString(item["author"]); author != "" {
+		normalized["author"] = author
+	} else if author := asString(item["author_name"]); author != "" {
+		normalized["author"] = author
+	}
+	if _, ok := item["creator_id"]; ok {
+		normalized["creator"] = r.userName(ctx, asInt(item["creator_id"]))
+	}
+	return normalized
+}
+
+// formatTimestamp renders a unix timestamp as an ISO 8601 date in loc, or "" when unset.
+func formatTimestamp(ts int, loc *time.Location) string {
+	if ts <= 0 {
+		return ""
+	}
+	return time.Unix(int64(ts), 0).In(loc).Format("2006-01-02T15:04:05Z07:00")
+}
+
+// asInt converts the loosely typed numbers returned by Kanboard (float64 or numeric strings) to int.
+func asInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return 0
+			}
+			return int(f)
+		}
+		return n
+	case bool:
+		if v {
+			return 1
+		}
+	}
+	return 0
+}
+
+// asFloat converts the loosely typed numbers returned by Kanboard to float64.
+func asFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	case bool:
+		if v {
+			return 1
+		}
+	}
+	return 0
+}
+
+// asString converts a scalar Kanboard value to its string form.
+func asString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		resultBytes, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(resultBytes)
+	}
+}
+
+// asList returns value as a list, treating Kanboard's false/null "no result" replies as empty.
+func asList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return nil
+}
+
+// asMap returns value as an object, or an empty map when it is not one.
+func asMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
+
+// sortFieldAliases maps the "sort_by" values of paginated tools to Kanboard fields.
+var sortFieldAliases = map[string]string{
+	"due_date":      "date_due",
+	"date_due":      "date_due",
+	"priority":      "priority",
+	"position":      "position",
+	"date_modified": "date_modification",
+	"modified":      "date_modification",
+	"created":       "date_creation",
+	"date_creation": "date_creation",
+	"id":            "id",
+	"title":         "title",
+	"name":          "name",
+	"username":      "username",
+}
+
+// descendingByDefault lists the sort fields where the most relevant items have the highest values.
+var descendingByDefault = map[string]bool{
+	"priority":          true,
+	"date_modification": true,
+	"date_creation":     true,
+}
+
+// withPagination adds the limit/offset/cursor and sorting arguments shared by paginated tools.
+func withPagination() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of items to return, 0 for all (optional)"),
+		)(t)
+		mcp.WithNumber("offset",
+			mcp.Description("Number of items to skip (optional)"),
+		)(t)
+		mcp.WithString("cursor",
+			mcp.Description("Opaque next_cursor value returned by a previous call; overrides offset (optional)"),
+		)(t)
+		mcp.WithString("sort_by",
+			mcp.Description("Sort by due_date, priority, position, date_modified, created, id, title, name or username (optional)"),
+		)(t)
+		mcp.WithString("sort_order",
+			mcp.Enum("asc", "desc"),
+			mcp.Description("Sort order, defaults to desc for priority and dates modified/created, asc otherwise (optional)"),
+		)(t)
+	}
+}
+
+// listPage is the envelope returned by paginated tools.
+type listPage struct {
+	Total      int         `json:"total"`
+	Offset     int         `json:"offset"`
+	Limit      int         `json:"limit"`
+	Count      int         `json:"count"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	Items      interface{} `json:"items"`
+}
+
+// formatPagedListResult sorts and slices a list payload before rendering it like formatListResult.
+// Without any pagination argument the output is identical to formatListResult.
+func (kc *kanboardClient) formatPagedListResult(ctx context.Context, request mcp.CallToolRequest, result interface{}, kind string) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+	paged := false
+	for _, key := range []string{"limit", "offset", "cursor", "sort_by", "sort_order"} {
+		if _, ok := args[key]; ok {
+			paged = true
+		}
+	}
+	if !paged {
+		return kc.formatListResult(ctx, request, result, kind)
+	}
+
+	items := asList(result)
+	limit := request.GetInt("limit", 0)
+	offset := request.GetInt("offset", 0)
+	if cursor := request.GetString("cursor", ""); cursor != "" {
+		decoded, err := decodeCursor(cursor)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		offset = decoded
+	}
+	if limit < 0 || offset < 0 {
+		return mcp.NewToolResultError("limit and offset must be >= 0"), nil
+	}
+
+	if sortBy := request.GetString("sort_by", ""); sortBy != "" {
+		field, ok := sortFieldAliases[sortBy]
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported sort_by '%s'", sortBy)), nil
+		}
+		descending := descendingByDefault[field]
+		switch request.GetString("sort_order", "") {
+		case "asc":
+			descending = false
+		case "desc":
+			descending = true
+		}
+		sortItems(items, field, descending)
+	}
+
+	page := listPage{Total: len(items), Offset: offset, Limit: limit}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+		page.NextCursor = encodeCursor(end)
+	}
+	pageItems := []interface{}{}
+	if offset < len(items) {
+		pageItems = items[offset:end]
+	}
+	page.Count = len(pageItems)
+
+	format := request.GetString("format", "json")
+	fields := request.GetStringSlice("fields", nil)
+	text, err := kc.renderList(ctx, pageItems, kind, format, fields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	switch format {
+	case "", "json", "compact":
+		page.Items = json.RawMessage(text)
+		var resultBytes []byte
+		if format == "compact" {
+			resultBytes, err = json.Marshal(page)
+		} else {
+			resultBytes, err = json.MarshalIndent(page, "", "  ")
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(resultBytes)), nil
+	default:
+		footer := fmt.Sprintf("\n_Showing %d of %d (offset %d)._", page.Count, page.Total, page.Offset)
+		if page.NextCursor != "" {
+			footer += fmt.Sprintf(" next_cursor: `%s`", page.NextCursor)
+		}
+		return mcp.NewToolResultText(strings.TrimRight(text, "\n") + "\n" + footer), nil
+	}
+}
+
+// sortItems sorts Kanboard objects in place by field. Numeric values are compared
+// numerically; unset due dates always sort last.
+func sortItems(items []interface{}, field string, descending bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a := asMap(items[i])[field]
+		b := asMap(items[j])[field]
+
+		if field == "date_due" {
+			aUnset, bUnset := asInt(a) == 0, asInt(b) == 0
+			if aUnset != bUnset {
+				return bUnset
+			}
+		}
+
+		as, bs := asString(a), asString(b)
+		af, aErr := strconv.ParseFloat(as, 64)
+		bf, bErr := strconv.ParseFloat(bs, 64)
+		if aErr == nil && bErr == nil {
+			if descending {
+				return af > bf
+			}
+			return af < bf
+		}
+		if descending {
+			return strings.ToLower(as) > strings.ToLower(bs)
+		}
+		return strings.ToLower(as) < strings.ToLower(bs)
+	})
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), "offset:") {
+		return 0, fmt.Errorf("invalid cursor '%s'", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "offset:"))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor '%s'", cursor)
+	}
+	return offset, nil
+}
+
+// lookupCacheTTL is how long read-only lookups (users, projects, columns...) are reused.
+const lookupCacheTTL = time.Minute
+
+// apiCache memoizes the results of read-only API calls for a short time. Callers get a
+// copy of the cached value, so they may modify what they receive.
+type apiCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]apiCacheEntry
+}
+
+type apiCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newAPICache(ttl time.Duration) *apiCache {
+	return &apiCache{ttl: ttl, entries: map[string]apiCacheEntry{}}
+}
+
+func (c *apiCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return copyJSONValue(entry.value), true
+}
+
+// set stores a copy of value and drops the entries that have expired.
+func (c *apiCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for cachedKey, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, cachedKey)
+		}
+	}
+	c.entries[key] = apiCacheEntry{value: copyJSONValue(value), expires: now.Add(c.ttl)}
+}
+
+// copyJSONValue deep-copies the maps and slices of a decoded JSON value.
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyJSONValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyJSONValue(item)
+		}
+		return copied
+	}
+	return value
+}
+
+// clear drops every entry, e.g. when a webhook reports that data changed.
+func (c *apiCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]apiCacheEntry{}
+}
+
+// callKanboardAPICached behaves like callKanboardAPI but reuses recent results of the
+// same method and params. Only use it for read-only lookups.
+func (kc *kanboardClient) callKanboardAPICached(ctx context.Context, method string, params interface{}) (interface{}, error) {
+	paramBytes, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	key := method + ":" + string(paramBytes)
+	if kc.cache != nil {
+		if value, ok := kc.cache.get(key); ok {
+			return value, nil
+		}
+	}
+
+	result, err := kc.callKanboardAPI(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+	if kc.cache != nil {
+		kc.cache.set(key, result)
+	}
+	return result, nil
+}
+
+// forEachConcurrent calls fn for every index in [0, n) with at most limit calls in flight.
+func forEachConcurrent(n, limit int, fn func(i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// joinErrors combines the failures collected by forEachConcurrent workers into one error,
+// or returns nil when every call succeeded.
+func joinErrors(errs []error) error {
+	var failures []string
+	for _, err := range errs {
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(failures, "; "))
+}
+
+// visibleProjects returns the projects the API user can see. The application API key
+// can list every project; a user session only sees its own projects.
+func (kc *kanboardClient) visibleProjects(ctx context.Context) (map[int]string, error) {
+	projects := map[int]string{}
+	if !kc.isValidAPIKey() {
+		result, err := kc.callKanboardAPICached(ctx, "getMyProjectsList", nil)
+		if err == nil {
+			for id, name := range asMap(result) {
+				projects[asInt(id)] = asString(name)
+			}
+			return projects, nil
+		}
+	}
+
+	result, err := kc.callKanboardAPICached(ctx, "getAllProjects", nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range asList(result) {
+		project := asMap(item)
+		projects[asInt(project["id"])] = asString(project["name"])
+	}
+	return projects, nil
+}
+
+// resolveProjectID returns the ID of the project with the given name.
+func (kc *kanboardClient) resolveProjectID(ctx context.Context, projectName string) (int, error) {
+	result, err := kc.callKanboardAPICached(ctx, "getProjectByName", map[string]string{"name": projectName})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get project ID: %v", err)
+	}
+	projectID := asInt(asMap(result)["id"])
+	if projectID == 0 {
+		return 0, fmt.Errorf("Project '%s' not found", projectName)
+	}
+	return projectID, nil
+}
+
+// resolveUserID accepts a user ID, a username, a full name, "me", or "nobody"/"unassigned" (0).
+func (kc *kanboardClient) resolveUserID(ctx context.Context, user string) (int, error) {
+	user = strings.TrimSpace(user)
+	switch strings.ToLower(user) {
+	case "", "nobody", "unassigned", "none":
+		return 0, nil
+	case "me":
+		result, err := kc.callKanboardAPICached(ctx, "getMe", nil)
+		if err != nil {
+			return 0, fmt.Errorf("Failed to get current user: %v", err)
+		}
+		return asInt(asMap(result)["id"]), nil
+	}
+	if id, err := strconv.Atoi(user); err == nil {
+		return id, nil
+	}
+
+	result, err := kc.callKanboardAPICached(ctx, "getAllUsers", nil)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get users: %v", err)
+	}
+	for _, item := range asList(result) {
+		candidate := asMap(item)
+		if strings.EqualFold(asString(candidate["username"]), user) || strings.EqualFold(asString(candidate["name"]), user) {
+			return asInt(candidate["id"]), nil
+		}
+	}
+	return 0, fmt.Errorf("User '%s' not found", user)
+}
+
+var (
+	relativeOffsetPattern = regexp.MustCompile(`^in (\d+|an?) (minute|hour|day|week|month|year)s?$`)
+	agoOffsetPattern      = regexp.MustCompile(`^(\d+|an?) (minute|hour|day|week|month|year)s? ago$`)
+	timeOfDayPattern      = regexp.MustCompile(`^(?:(.*?)\s+)?(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// parseDateExpr parses ISO 8601 dates and times, dates followed by an explicit zone
+// ("2024-05-01 17:00 Europe/Paris", "... +02:00", "... UTC") and relative expressions such
+// as "today", "tomorrow 9am", "next Friday", "in 3 days", "2 weeks ago", "end of week",
+// "end of month" and "end of sprint". The result is expressed in loc; hasTime reports
+// whether the value carried a time of day. sprintEnd resolves "end of sprint" and may be nil.
+func parseDateExpr(value string, now time.Time, loc *time.Location, sprintEnd func() (time.Time, error)) (result time.Time, hasTime bool, err error) {
+	value = strings.TrimSpace(value)
+	now = now.In(loc)
+
+	// Absolute dates, optionally followed by an IANA zone name.
+	zone := loc
+	text := value
+	if i := strings.LastIndex(value, " "); i > 0 {
+		if name := value[i+1:]; strings.Contains(name, "/") || name == "UTC" {
+			if named, err := time.LoadLocation(name); err == nil {
+				zone, text = named, value[:i]
+			}
+		}
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04Z07:00", "2006-01-02 15:04 Z07:00"} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t.In(loc), true, nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, text, zone); err == nil {
+			return t.In(loc), true, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", text, zone); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), false, nil
+	}
+	// Kanboard's own input formats (m/d/Y is its default application date format).
+	for _, layout := range []string{"1/2/2006", "2006/1/2", "2.1.2006", "2006_01_02"} {
+		if t, err := time.ParseInLocation(layout+" 15:04", text, zone); err == nil {
+			return t.In(loc), true, nil
+		}
+		if t, err := time.ParseInLocation(layout, text, zone); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), false, nil
+		}
+	}
+
+	// Relative expressions, optionally followed by a time of day ("tomorrow at 17:30").
+	lower := strings.ToLower(strings.Join(strings.Fields(value), " "))
+	if t, ok, err := relativeDate(lower, now, sprintEnd); ok || err != nil {
+		return t, false, err
+	}
+	if lower == "now" {
+		return now, true, nil
+	}
+	if match := relativeOffsetPattern.FindStringSubmatch(lower); match != nil {
+		return addDateUnits(now, match[1], match[2], 1), match[2] == "minute" || match[2] == "hour", nil
+	}
+	if match := agoOffsetPattern.FindStringSubmatch(lower); match != nil {
+		return addDateUnits(now, match[1], match[2], -1), match[2] == "minute" || match[2] == "hour", nil
+	}
+	if match := timeOfDayPattern.FindStringSubmatch(lower); match != nil {
+		day := now
+		if match[1] != "" {
+			var ok bool
+			day, ok, err = relativeDate(strings.TrimSuffix(match[1], " at"), now, sprintEnd)
+			if err != nil {
+				return time.Time{}, false, err
+			}
+			if !ok {
+				day, _, err = parseDateExpr(strings.TrimSuffix(match[1], " at"), now, loc, sprintEnd)
+				if err != nil {
+					return time.Time{}, false, err
+				}
+			}
+		}
+		hour, _ := strconv.Atoi(match[2])
+		minute, _ := strconv.Atoi(match[3])
+		if match[4] == "pm" && hour < 12 {
+			hour += 12
+		} else if match[4] == "am" && hour == 12 {
+			hour = 0
+		}
+		if hour < 24 && minute < 60 && (match[3] != "" || match[4] != "") {
+			return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc), true, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD, YYYY-MM-DD HH:MM, MM/DD/YYYY, ISO 8601 or an expression like 'tomorrow', 'next Friday', 'in 3 days', 'end of sprint')", value)
+}
+
+// relativeDate resolves day-level expressions relative to now. ok is false when lower
+// isn't one of them.
+func relativeDate(lower string, now time.Time, sprintEnd func() (time.Time, error)) (time.Time, bool, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch lower {
+	case "today":
+		return today, true, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true, nil
+	case "next week":
+		return today.AddDate(0, 0, daysUntil(today.Weekday(), time.Monday, false)), true, nil
+	case "end of week", "end of the week":
+		return today.AddDate(0, 0, daysUntil(today.Weekday(), time.Friday, true)), true, nil
+	case "end of month", "end of the month":
+		return time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()), true, nil
+	case "next month":
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location()), true, nil
+	case "end of sprint", "end of the sprint", "sprint end":
+		if sprintEnd == nil {
+			return time.Time{}, false, fmt.Errorf("'%s' needs a project with an active sprint", lower)
+		}
+		end, err := sprintEnd()
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		return time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, now.Location()), true, nil
+	}
+
+	words := strings.Fields(lower)
+	switch {
+	case len(words) == 1:
+		if weekday, ok := weekdays[words[0]]; ok {
+			return today.AddDate(0, 0, daysUntil(today.Weekday(), weekday, false)), true, nil
+		}
+	case len(words) == 2 && (words[0] == "next" || words[0] == "this" || words[0] == "last"):
+		weekday, ok := weekdays[words[1]]
+		if !ok {
+			return time.Time{}, false, nil
+		}
+		switch words[0] {
+		case "this":
+			return today.AddDate(0, 0, daysUntil(today.Weekday(), weekday, true)), true, nil
+		case "next":
+			return today.AddDate(0, 0, daysUntil(today.Weekday(), weekday, false)), true, nil
+		default:
+			return today.AddDate(0, 0, daysUntil(today.Weekday(), weekday, false)-7), true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// daysUntil counts the days from one weekday to the next occurrence of another; with
+// includeToday, a matching weekday means today (0) rather than a week later.
+func daysUntil(from, to time.Weekday, includeToday bool) int {
+	days := (int(to) - int(from) + 7) % 7
+	if days == 0 && !includeToday {
+		days = 7
+	}
+	return days
+}
+
+func addDateUnits(t time.Time, amount, unit string, sign int) time.Time {
+	n, err := strconv.Atoi(amount)
+	if err != nil {
+		n = 1 // "a" or "an"
+	}
+	n *= sign
+	switch unit {
+	case "minute":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "hour":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "week":
+		return t.AddDate(0, 0, 7*n)
+	case "month":
+		return t.AddDate(0, n, 0)
+	case "year":
+		return t.AddDate(n, 0, 0)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// location returns the Kanboard instance timezone from getTimezone, or the server's
+// local timezone when it can't be loaded.
+func (kc *kanboardClient) location(ctx context.Context) *time.Location {
+	result, err := kc.callKanboardAPICached(ctx, "getTimezone", nil)
+	if err != nil {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(asString(result))
+	if err != nil || asString(result) == "" {
+		return time.Local
+	}
+	return loc
+}
+
+// kanboardDate parses a date argument in the instance timezone and formats it the way
+// Kanboard's date parser accepts it ("YYYY-MM-DD HH:MM", or "YYYY-MM-DD" for date-only
+// values, or always date-only with dateOnly). "end of sprint" refers to the active sprint
+// of projectID, which may be resolved lazily through projectOf.
+func (kc *kanboardClient) kanboardDate(ctx context.Context, value string, dateOnly bool, projectOf func() (int, error)) (string, error) {
+	sprintEnd := func() (time.Time, error) {
+		projectID, err := projectOf()
+		if err != nil {
+			return time.Time{}, err
+		}
+		sprints, err := kc.projectSprints(ctx, projectID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		now := time.Now()
+		for _, sprint := range sprints {
+			if !sprint.IsCompleted && (sprint.IsActive || (!now.Before(sprint.Start) && !now.After(sprint.End))) {
+				return sprint.End, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("project %d has no active sprint", projectID)
+	}
+	if projectOf == nil {
+		sprintEnd = nil
+	}
+
+	parsed, hasTime, err := parseDateExpr(value, time.Now(), kc.location(ctx), sprintEnd)
+	if err != nil {
+		return "", err
+	}
+	if dateOnly || !hasTime {
+		return parsed.Format("2006-01-02"), nil
+	}
+	return parsed.Format("2006-01-02 15:04"), nil
+}
+
+// taskProject returns a lazy lookup of a task's project for kanboardDate.
+func (kc *kanboardClient) taskProject(ctx context.Context, taskID int) func() (int, error) {
+	return func() (int, error) {
+		result, err := kc.callKanboardAPI(ctx, "getTask", map[string]int{"task_id": taskID})
+		if err != nil {
+			return 0, fmt.Errorf("Failed to get task: %v", err)
+		}
+		return asInt(asMap(result)["project_id"]), nil
+	}
+}
+
+// taskQuery holds the filters of the query_tasks tool. Zero values mean "no filter".
+type taskQuery struct {
+	assignee        string
+	column          string
+	swimlane        string
+	category        string
+	tags            []string
+	allTags         bool
+	priorityMin     *int
+	priorityMax     *int
+	scoreMin        *int
+	scoreMax        *int
+	dueBefore       time.Time
+	dueAfter        time.Time
+	modifiedSince   time.Time
+	hasOpenSubtasks *bool
+	metadata        map[string]string
+	text            string
+}
+
+func optionalInt(request mcp.CallToolRequest, key string) *int {
+	if _, ok := request.GetArguments()[key]; !ok {
+		return nil
+	}
+	value := request.GetInt(key, 0)
+	return &value
+}
+
+func optionalBool(request mcp.CallToolRequest, key string) *bool {
+	if _, ok := request.GetArguments()[key]; !ok {
+		return nil
+	}
+	value := request.GetBool(key, false)
+	return &value
+}
+
+func parseTaskQuery(request mcp.CallToolRequest, loc *time.Location) (*taskQuery, error) {
+	query := &taskQuery{
+		assignee:        request.GetString("assignee", ""),
+		column:          request.GetString("column", ""),
+		swimlane:        request.GetString("swimlane", ""),
+		category:        request.GetString("category", ""),
+		tags:            request.GetStringSlice("tags", nil),
+		allTags:         request.GetString("tags_match", "any") == "all",
+		priorityMin:     optionalInt(request, "priority_min"),
+		priorityMax:     optionalInt(request, "priority_max"),
+		scoreMin:        optionalInt(request, "score_min"),
+		scoreMax:        optionalInt(request, "score_max"),
+		hasOpenSubtasks: optionalBool(request, "has_open_subtasks"),
+		text:            strings.ToLower(request.GetString("text", "")),
+	}
+
+	dates := map[string]*time.Time{
+		"due_before":     &query.dueBefore,
+		"due_after":      &query.dueAfter,
+		"modified_since": &query.modifiedSince,
+	}
+	for key, target := range dates {
+		if value := request.GetString(key, ""); value != "" {
+			parsed, _, err := parseDateExpr(value, time.Now(), loc, nil)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			*target = parsed
+		}
+	}
+
+	for _, pair := range request.GetStringSlice("metadata", nil) {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("metadata filter '%s' must be in key=value form", pair)
+		}
+		if query.metadata == nil {
+			query.metadata = map[string]string{}
+		}
+		query.metadata[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return query, nil
+}
+
+// queryGroupFields maps the "group_by" values of query_tasks to normalized task fields.
+var queryGroupFields = map[string]string{
+	"project":  "project",
+	"column":   "column",
+	"swimlane": "swimlane",
+	"assignee": "assignee",
+	"category": "category",
+	"priority": "priority",
+}
+
+// queryTasks collects the tasks of the given projects and applies the query filters.
+// Cheap filters run first; tags, metadata and subtasks are only fetched for the remaining tasks.
+func (kc *kanboardClient) queryTasks(ctx context.Context, projectIDs []int, status string, query *taskQuery) ([]interface{}, error) {
+	var statuses []int
+	switch status {
+	case "", "open":
+		statuses = []int{1}
+	case "closed":
+		statuses = []int{0}
+	case "all":
+		statuses = []int{1, 0}
+	default:
+		return nil, fmt.Errorf("invalid status '%s' (expected open, closed or all)", status)
+	}
+
+	assigneeID := -1
+	if query.assignee != "" {
+		id, err := kc.resolveUserID(ctx, query.assignee)
+		if err != nil {
+			return nil, err
+		}
+		assigneeID = id
+	}
+
+	var tasks []interface{}
+	for _, projectID := range projectIDs {
+		for _, statusID := range statuses {
+			result, err := kc.callKanboardAPI(ctx, "getAllTasks", map[string]int{"project_id": projectID, "status_id": statusID})
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get tasks for project %d: %v", projectID, err)
+			}
+			tasks = append(tasks, asList(result)...)
+		}
+	}
+
+	resolver := kc.newNameResolver(ctx)
+	var candidates []interface{}
+	for _, item := range tasks {
+		task := asMap(item)
+		normalized := resolver.normalize(ctx, task)
+
+		if assigneeID >= 0 && asInt(task["owner_id"]) != assigneeID {
+			continue
+		}
+		if query.column != "" && !strings.EqualFold(asString(normalized["column"]), query.column) {
+			continue
+		}
+		if query.swimlane != "" && !strings.EqualFold(asString(normalized["swimlane"]), query.swimlane) {
+			continue
+		}
+		if query.category != "" && !strings.EqualFold(asString(normalized["category"]), query.category) {
+			continue
+		}
+		priority, score := asInt(task["priority"]), asInt(task["score"])
+		if query.priorityMin != nil && priority < *query.priorityMin {
+			continue
+		}
+		if query.priorityMax != nil && priority > *query.priorityMax {
+			continue
+		}
+		if query.scoreMin != nil && score < *query.scoreMin {
+			continue
+		}
+		if query.scoreMax != nil && score > *query.scoreMax {
+			continue
+		}
+		due := int64(asInt(task["date_due"]))
+		if !query.dueBefore.IsZero() && (due == 0 || due >= query.dueBefore.Unix()) {
+			continue
+		}
+		if !query.dueAfter.IsZero() && (due == 0 || due <= query.dueAfter.Unix()) {
+			continue
+		}
+		if !query.modifiedSince.IsZero() && int64(asInt(task["date_modification"])) < query.modifiedSince.Unix() {
+			continue
+		}
+		if query.text != "" &&
+			!strings.Contains(strings.ToLower(asString(task["title"])), query.text) &&
+			!strings.Contains(strings.ToLower(asString(task["description"])), query.text) &&
+			!strings.Contains(strings.ToLower(asString(task["reference"])), query.text) {
+			continue
+		}
+		candidates = append(candidates, item)
+	}
+
+	if len(query.tags) == 0 && len(query.metadata) == 0 && query.hasOpenSubtasks == nil {
+		return candidates, nil
+	}
+
+	keep := make([]bool, len(candidates))
+	errs := make([]error, len(candidates))
+	forEachConcurrent(len(candidates), 8, func(i int) {
+		taskID := asInt(asMap(candidates[i])["id"])
+		matches, err := kc.matchTaskDetails(ctx, taskID, query)
+		keep[i], errs[i] = matches, err
+	})
+
+	var filtered []interface{}
+	for i, item := range candidates {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if keep[i] {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered, nil
+}
+
+// matchTaskDetails applies the filters that need one API call per task.
+func (kc *kanboardClient) matchTaskDetails(ctx context.Context, taskID int, query *taskQuery) (bool, error) {
+	if len(query.tags) > 0 {
+		result, err := kc.callKanboardAPI(ctx, "getTaskTags", map[string]int{"task_id": taskID})
+		if err != nil {
+			return false, fmt.Errorf("Failed to get tags of task %d: %v", taskID, err)
+		}
+		taskTags := map[string]bool{}
+		for _, tag := range asMap(result) {
+			taskTags[strings.ToLower(asString(tag))] = true
+		}
+		matched := 0
+		for _, tag := range query.tags {
+			if taskTags[strings.ToLower(tag)] {
+				matched++
+			}
+		}
+		if matched == 0 || (query.allTags && matched < len(query.tags)) {
+			return false, nil
+		}
+	}
+
+	if len(query.metadata) > 0 {
+		result, err := kc.callKanboardAPI(ctx, "getTaskMetadata", map[string]int{"task_id": taskID})
+		if err != nil {
+			return false, fmt.Errorf("Failed to get metadata of task %d: %v", taskID, err)
+		}
+		metadata := asMap(result)
+		for key, value := range query.metadata {
+			actual, ok := metadata[key]
+			if !ok || (value != "*" && asString(actual) != value) {
+				return false, nil
+			}
+		}
+	}
+
+	if query.hasOpenSubtasks != nil {
+		result, err := kc.callKanboardAPI(ctx, "getAllSubtasks", map[string]int{"task_id": taskID})
+		if err != nil {
+			return false, fmt.Errorf("Failed to get subtasks of task %d: %v", taskID, err)
+		}
+		open := false
+		for _, item := range asList(result) {
+			if asInt(asMap(item)["status"]) != 2 {
+				open = true
+				break
+			}
+		}
+		if open != *query.hasOpenSubtasks {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// queryProjectIDs resolves the project_ids/project_names arguments, defaulting to every visible project.
+func (kc *kanboardClient) queryProjectIDs(ctx context.Context, request mcp.CallToolRequest) ([]int, error) {
+	projectIDs := request.GetIntSlice("project_ids", nil)
+	for _, name := range request.GetStringSlice("project_names", nil) {
+		projectID, err := kc.resolveProjectID(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+	if len(projectIDs) > 0 {
+		return projectIDs, nil
+	}
+
+	projects, err := kc.visibleProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list projects: %v", err)
+	}
+	for projectID := range projects {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Ints(projectIDs)
+	return projectIDs, nil
+}
+
+func (kc *kanboardClient) queryTasksHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := parseTaskQuery(request, kc.location(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	projectIDs, err := kc.queryProjectIDs(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	tasks, err := kc.queryTasks(ctx, projectIDs, request.GetString("status", "open"), query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if sortBy := request.GetString("sort_by", ""); sortBy != "" {
+		field, ok := sortFieldAliases[sortBy]
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported sort_by '%s'", sortBy)), nil
+		}
+		descending := descendingByDefault[field]
+		switch request.GetString("sort_order", "") {
+		case "asc":
+			descending = false
+		case "desc":
+			descending = true
+		}
+		sortItems(tasks, field, descending)
+	}
+	if limit := request.GetInt("limit", 0); limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	format := request.GetString("format", "json")
+	fields := request.GetStringSlice("fields", nil)
+
+	groupBy := request.GetString("group_by", "")
+	if groupBy == "" {
+		text, err := kc.renderList(ctx, tasks, "task", format, fields)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	}
+
+	groupField, ok := queryGroupFields[groupBy]
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported group_by '%s'", groupBy)), nil
+	}
+	resolver := kc.newNameResolver(ctx)
+	var groupKeys []string
+	groups := map[string][]interface{}{}
+	for _, item := range tasks {
+		key := asString(resolver.normalize(ctx, asMap(item))[groupField])
+		if key == "" {
+			key = "(none)"
+		}
+		if _, seen := groups[key]; !seen {
+			groupKeys = append(groupKeys, key)
+		}
+		groups[key] = append(groups[key], item)
+	}
+
+	switch format {
+	case "", "json", "compact":
+		type taskGroup struct {
+			Key   string          `json:"key"`
+			Count int             `json:"count"`
+			Items json.RawMessage `json:"items"`
+		}
+		output := struct {
+			Total  int         `json:"total"`
+			Groups []taskGroup `json:"groups"`
+		}{Total: len(tasks)}
+		for _, key := range groupKeys {
+			text, err := kc.renderList(ctx, groups[key], "task", format, fields)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			output.Groups = append(output.Groups, taskGroup{Key: key, Count: len(groups[key]), Items: json.RawMessage(text)})
+		}
+		var resultBytes []byte
+		if format == "compact" {
+			resultBytes, err = json.Marshal(output)
+		} else {
+			resultBytes, err = json.MarshalIndent(output, "", "  ")
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(resultBytes)), nil
+	default:
+		var sb strings.Builder
+		for _, key := range groupKeys {
+			text, err := kc.renderList(ctx, groups[key], "task", format, fields)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			fmt.Fprintf(&sb, "## %s (%d)\n\n%s\n", key, len(groups[key]), strings.TrimRight(text, "\n"))
+			sb.WriteString("\n")
+		}
+		return mcp.NewToolResultText(strings.TrimRight(sb.String(), "\n")), nil
+	}
+}
+
+// joinValues joins scalar values with sep.
+func joinValues(values []interface{}, sep string) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = asString(value)
+	}
+	return strings.Join(parts, sep)
+}
+
+// searchTerms extracts the free-text words of a Kanboard search query, skipping
+// filter expressions such as "assignee:me".
+func searchTerms(query string) []string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		if strings.Contains(word, ":") {
+			continue
+		}
+		word = strings.ToLower(strings.Trim(word, "\"'"))
+		if word != "" {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// rankTask scores how well a task matches the search terms: phrase matches in the
+// title weigh most, then single terms in the title, the reference and the description.
+func rankTask(task map[string]interface{}, terms []string) int {
+	rank := 1
+	title := strings.ToLower(asString(task["title"]))
+	description := strings.ToLower(asString(task["description"]))
+	reference := strings.ToLower(asString(task["reference"]))
+
+	if len(terms) > 1 && strings.Contains(title, strings.Join(terms, " ")) {
+		rank += 10
+	}
+	for _, term := range terms {
+		if strings.Contains(title, term) {
+			rank += 3
+		}
+		if reference != "" && strings.Contains(reference, term) {
+			rank += 5
+		}
+		if strings.Contains(description, term) {
+			rank++
+		}
+	}
+	if asInt(task["is_active"]) == 1 {
+		rank++
+	}
+	return rank
+}
+
+// containsAnyTerm reports whether text contains at least one of the terms.
+func containsAnyTerm(text string, terms []string) bool {
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		if strings.Contains(text, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSearchConcurrency caps the number of projects search_all queries in parallel, so a
+// large "concurrency" argument cannot flood the Kanboard instance.
+const maxSearchConcurrency = 16
+
+// searchHit is one merged search_all result.
+type searchHit struct {
+	task      map[string]interface{}
+	rank      int
+	matchedIn []string
+}
+
+func (h *searchHit) addMatch(where string, rank int) {
+	h.rank += rank
+	for _, existing := range h.matchedIn {
+		if existing == where {
+			return
+		}
+	}
+	h.matchedIn = append(h.matchedIn, where)
+}
+
+func (kc *kanboardClient) searchAllHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := request.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	includeComments := request.GetBool("include_comments", false)
+	includeSubtasks := request.GetBool("include_subtasks", false)
+	concurrency := min(request.GetInt("concurrency", 4), maxSearchConcurrency)
+	limit := request.GetInt("limit", 50)
+
+	projects, err := kc.visibleProjects(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list projects: %v", err)), nil
+	}
+	projectIDs := make([]int, 0, len(projects))
+	for projectID := range projects {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Ints(projectIDs)
+
+	terms := searchTerms(query)
+	var mu sync.Mutex
+	hits := map[int]*searchHit{}
+	var failures []string
+
+	record := func(task map[string]interface{}, where string, rank int) {
+		mu.Lock()
+		defer mu.Unlock()
+		taskID := asInt(task["id"])
+		hit, ok := hits[taskID]
+		if !ok {
+			hit = &searchHit{task: task}
+			hits[taskID] = hit
+		}
+		hit.addMatch(where, rank)
+	}
+	fail := func(projectID int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, fmt.Sprintf("project %d: %v", projectID, err))
+	}
+
+	forEachConcurrent(len(projectIDs), concurrency, func(i int) {
+		projectID := projectIDs[i]
+		result, err := kc.callKanboardAPI(ctx, "searchTasks", map[string]interface{}{"project_id": projectID, "query": query})
+		if err != nil {
+			fail(projectID, err)
+			return
+		}
+		for _, item := range asList(result) {
+			task := asMap(item)
+			record(task, "task", rankTask(task, terms))
+		}
+
+		if (!includeComments && !includeSubtasks) || len(terms) == 0 {
+			return
+		}
+		tasks, err := kc.callKanboardAPI(ctx, "getAllTasks", map[string]int{"project_id": projectID, "status_id": 1})
+		if err != nil {
+			fail(projectID, err)
+			return
+		}
+		for _, item := range asList(tasks) {
+			task := asMap(item)
+			taskID := asInt(task["id"])
+			if includeComments {
+				comments, err := kc.callKanboardAPI(ctx, "getAllComments", map[string]int{"task_id": taskID})
+				if err != nil {
+					fail(projectID, err)
+					return
+				}
+				for _, comment := range asList(comments) {
+					if containsAnyTerm(asString(asMap(comment)["comment"]), terms) {
+						record(task, "comments", 2)
+					}
+				}
+			}
+			if includeSubtasks {
+				subtasks, err := kc.callKanboardAPI(ctx, "getAllSubtasks", map[string]int{"task_id": taskID})
+				if err != nil {
+					fail(projectID, err)
+					return
+				}
+				for _, subtask := range asList(subtasks) {
+					if containsAnyTerm(asString(asMap(subtask)["title"]), terms) {
+						record(task, "subtasks", 2)
+					}
+				}
+			}
+		}
+	})
+
+	ranked := make([]*searchHit, 0, len(hits))
+	for _, hit := range hits {
+		ranked = append(ranked, hit)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].rank != ranked[j].rank {
+			return ranked[i].rank > ranked[j].rank
+		}
+		return asInt(ranked[i].task["date_modification"]) > asInt(ranked[j].task["date_modification"])
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	items := make([]interface{}, len(ranked))
+	for i, hit := range ranked {
+		task := make(map[string]interface{}, len(hit.task)+2)
+		for key, value := range hit.task {
+			task[key] = value
+		}
+		matchedIn := make([]interface{}, len(hit.matchedIn))
+		for j, where := range hit.matchedIn {
+			matchedIn[j] = where
+		}
+		task["rank"] = hit.rank
+		task["matched_in"] = matchedIn
+		if _, ok := task["project_name"]; !ok {
+			task["project_name"] = projects[asInt(task["project_id"])]
+		}
+		items[i] = task
+	}
+
+	format := request.GetString("format", "json")
+	text, err := kc.renderList(ctx, items, "search", format, request.GetStringSlice("fields", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(failures) == 0 {
+		return mcp.NewToolResultText(text), nil
+	}
+
+	sort.Strings(failures)
+	switch format {
+	case "", "json", "compact":
+		envelope := map[string]interface{}{"items": json.RawMessage(text), "failures": failures}
+		var resultBytes []byte
+		if format == "compact" {
+			resultBytes, err = json.Marshal(envelope)
+		} else {
+			resultBytes, err = json.MarshalIndent(envelope, "", "  ")
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(resultBytes)), nil
+	default:
+		return mcp.NewToolResultText(text + "\n\nSome projects could not be searched:\n- " + strings.Join(failures, "\n- ")), nil
+	}
+}
+
+// taskDetailCalls are the per-task API calls gathered by get_task_full, keyed by document section.
+var taskDetailCalls = []struct {
+	section string
+	method  string
+}{
+	{"task", "getTask"},
+	{"comments", "getAllComments"},
+	{"subtasks", "getAllSubtasks"},
+	{"internal_links", "getAllTaskLinks"},
+	{"external_links", "getAllExternalTaskLinks"},
+	{"tags", "getTaskTags"},
+	{"metadata", "getTaskMetadata"},
+	{"files", "getAllTaskFiles"},
+}
+
+// getTaskFull fetches a task and everything attached to it in parallel and returns
+// one document with resolved names and ISO dates.
+func (kc *kanboardClient) getTaskFull(ctx context.Context, taskID int) (map[string]interface{}, error) {
+	results := make([]interface{}, len(taskDetailCalls))
+	errs := make([]error, len(taskDetailCalls))
+	forEachConcurrent(len(taskDetailCalls), len(taskDetailCalls), func(i int) {
+		results[i], errs[i] = kc.callKanboardAPI(ctx, taskDetailCalls[i].method, map[string]int{"task_id": taskID})
+	})
+
+	if errs[0] != nil {
+		return nil, fmt.Errorf("Failed to get task details: %v", errs[0])
+	}
+	task := asMap(results[0])
+	if len(task) == 0 {
+		return nil, fmt.Errorf("Task %d not found", taskID)
+	}
+
+	resolver := kc.newNameResolver(ctx)
+	document := map[string]interface{}{}
+	failures := map[string]string{}
+
+	normalizedTask := resolver.normalize(ctx, task)
+	if asInt(task["is_active"]) == 1 {
+		normalizedTask["status"] = "open"
+	} else {
+		normalizedTask["status"] = "closed"
+	}
+	document["task"] = normalizedTask
+
+	for i, call := range taskDetailCalls[1:] {
+		i++
+		if errs[i] != nil {
+			failures[call.section] = errs[i].Error()
+			continue
+		}
+		switch call.section {
+		case "tags":
+			var tags []string
+			for _, name := range asMap(results[i]) {
+				tags = append(tags, asString(name))
+			}
+			sort.Strings(tags)
+			document["tags"] = tags
+		case "metadata":
+			document["metadata"] = asMap(results[i])
+		default:
+			items := []map[string]interface{}{}
+			for _, item := range asList(results[i]) {
+				items = append(items, resolver.normalize(ctx, asMap(item)))
+			}
+			document[call.section] = items
+		}
+	}
+	if len(failures) > 0 {
+		document["errors"] = failures
+	}
+	return document, nil
+}
+
+func (kc *kanboardClient) getTaskFullHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	taskId, err := request.RequireInt("task_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	document, err := kc.getTaskFull(ctx, taskId)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if request.GetString("format", "json") == "markdown" {
+		return mcp.NewToolResultText(renderTaskMarkdown(document)), nil
+	}
+
+	resultBytes, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+// documentItems returns a section of a get_task_full document as a list of objects.
+func documentItems(document map[string]interface{}, section string) []map[string]interface{} {
+	items, _ := document[section].([]map[string]interface{})
+	return items
+}
+
+func renderTaskMarkdown(document map[string]interface{}) string {
+	task := asMap(document["task"])
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# #%s %s\n\n", asString(task["id"]), asString(task["title"]))
+	details := []struct{ label, key string }{
+		{"Status", "status"},
+		{"Project", "project"},
+		{"Column", "column"},
+		{"Swimlane", "swimlane"},
+		{"Category", "category"},
+		{"Assignee", "assignee"},
+		{"Creator", "creator"},
+		{"Priority", "priority"},
+		{"Score", "score"},
+		{"Reference", "reference"},
+		{"Created", "date_creation"},
+		{"Modified", "date_modification"},
+		{"Started", "date_started"},
+		{"Due", "date_due"},
+		{"Completed", "date_completed"},
+	}
+	for _, detail := range details {
+		if value := asString(task[detail.key]); value != "" && value != "0" {
+			fmt.Fprintf(&sb, "- **%s:** %s\n", detail.label, value)
+		}
+	}
+	if tags, ok := document["tags"].([]string); ok && len(tags) > 0 {
+		fmt.Fprintf(&sb, "- **Tags:** %s\n", strings.Join(tags, ", "))
+	}
+	if description := strings.TrimSpace(asString(task["description"])); description != "" {
+		fmt.Fprintf(&sb, "\n## Description\n\n%s\n", description)
+	}
+
+	if subtasks := documentItems(document, "subtasks"); len(subtasks) > 0 {
+		sb.WriteString("\n## Subtasks\n\n")
+		for _, subtask := range subtasks {
+			check := " "
+			if asInt(subtask["status"]) == 2 {
+				check = "x"
+			}
+			fmt.Fprintf(&sb, "- [%s] %s", check, asString(subtask["title"]))
+			if assignee := asString(subtask["assignee"]); assignee != "" {
+				fmt.Fprintf(&sb, " @%s", assignee)
+			}
+			fmt.Fprintf(&sb, " (%sh/%sh)\n", asString(subtask["time_spent"]), asString(subtask["time_estimated"]))
+		}
+	}
+
+	if links := documentItems(document, "internal_links"); len(links) > 0 {
+		sb.WriteString("\n## Linked tasks\n\n")
+		for _, link := range links {
+			fmt.Fprintf(&sb, "- %s #%s %s", asString(link["label"]), asString(link["task_id"]), asString(link["title"]))
+			if column := asString(link["column_title"]); column != "" {
+				fmt.Fprintf(&sb, " [%s]", column)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if links := documentItems(document, "external_links"); len(links) > 0 {
+		sb.WriteString("\n## External links\n\n")
+		for _, link := range links {
+			fmt.Fprintf(&sb, "- [%s](%s) (%s)\n", asString(link["title"]), asString(link["url"]), asString(link["dependency_label"]))
+		}
+	}
+
+	if files := documentItems(document, "files"); len(files) > 0 {
+		sb.WriteString("\n## Files\n\n")
+		for _, file := range files {
+			fmt.Fprintf(&sb, "- %s (%s bytes)\n", asString(file["name"]), asString(file["size"]))
+		}
+	}
+
+	if metadata := asMap(document["metadata"]); len(metadata) > 0 {
+		sb.WriteString("\n## Metadata\n\n")
+		keys := make([]string, 0, len(metadata))
+		for key := range metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&sb, "- %s: %s\n", key, asString(metadata[key]))
+		}
+	}
+
+	if comments := documentItems(document, "comments"); len(comments) > 0 {
+		sb.WriteString("\n## Comments\n\n")
+		for _, comment := range comments {
+			fmt.Fprintf(&sb, "**%s** (%s):\n%s\n\n", asString(comment["author"]), asString(comment["date_creation"]), strings.TrimSpace(asString(comment["comment"])))
+		}
+	}
+
+	if failures := document["errors"]; failures != nil {
+		sb.WriteString("\n## Errors\n\n")
+		for section, message := range failures.(map[string]string) {
+			fmt.Fprintf(&sb, "- %s: %s\n", section, message)
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// boardColumn is one column of one swimlane as returned by getBoard.
+type boardColumn struct {
+	ID        int
+	Title     string
+	Position  int
+	TaskLimit int
+	Tasks     []map[string]interface{}
+}
+
+// boardSwimlane is one swimlane of a board with its columns.
+type boardSwimlane struct {
+	ID      int
+	Name    string
+	Columns []boardColumn
+}
+
+// fetchBoard returns the swimlanes, columns and active tasks of a project's board.
+func (kc *kanboardClient) fetchBoard(ctx context.Context, projectID int) ([]boardSwimlane, error) {
+	result, err := kc.callKanboardAPI(ctx, "getBoard", []int{projectID})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get board details: %v", err)
+	}
+
+	var swimlanes []boardSwimlane
+	for _, item := range asList(result) {
+		rawSwimlane := asMap(item)
+		swimlane := boardSwimlane{ID: asInt(rawSwimlane["id"]), Name: asString(rawSwimlane["name"])}
+		for _, columnItem := range asList(rawSwimlane["columns"]) {
+			rawColumn := asMap(columnItem)
+			column := boardColumn{
+				ID:        asInt(rawColumn["id"]),
+				Title:     asString(rawColumn["title"]),
+				Position:  asInt(rawColumn["position"]),
+				TaskLimit: asInt(rawColumn["task_limit"]),
+			}
+			for _, taskItem := range asList(rawColumn["tasks"]) {
+				column.Tasks = append(column.Tasks, asMap(taskItem))
+			}
+			sort.SliceStable(column.Tasks, func(i, j int) bool {
+				return asInt(column.Tasks[i]["position"]) < asInt(column.Tasks[j]["position"])
+			})
+			swimlane.Columns = append(swimlane.Columns, column)
+		}
+		swimlanes = append(swimlanes, swimlane)
+	}
+	return swimlanes, nil
+}
+
+// columnTaskCounts returns the number of active tasks per column across all swimlanes,
+// which is what Kanboard compares against a column's task_limit.
+func columnTaskCounts(swimlanes []boardSwimlane) map[int]int {
+	counts := map[int]int{}
+	for _, swimlane := range swimlanes {
+		for _, column := range swimlane.Columns {
+			counts[column.ID] += len(column.Tasks)
+		}
+	}
+	return counts
+}
+
+// wipLabel renders a column's WIP count, e.g. "4/3 over limit" or "2" when unlimited.
+func wipLabel(count, limit int) string {
+	if limit <= 0 {
+		return strconv.Itoa(count)
+	}
+	label := fmt.Sprintf("%d/%d", count, limit)
+	if count > limit {
+		label += " over limit"
+	}
+	return label
+}
+
+// taskBadge renders a board task as "#12 Title @alice due 2024-01-31", with the due date
+// in the location of now.
+func taskBadge(task map[string]interface{}, now time.Time) string {
+	badge := fmt.Sprintf("#%s %s", asString(task["id"]), asString(task["title"]))
+	assignee := asString(task["assignee_username"])
+	if assignee == "" {
+		assignee = asString(task["assignee_name"])
+	}
+	if assignee != "" {
+		badge += " @" + assignee
+	}
+	if due := asInt(task["date_due"]); due > 0 {
+		dueTime := time.Unix(int64(due), 0).In(now.Location())
+		badge += " due " + dueTime.Format("2006-01-02")
+		if dueTime.Before(now) {
+			badge += " (overdue)"
+		}
+	}
+	if total := asInt(task["nb_subtasks"]); total > 0 {
+		badge += fmt.Sprintf(" [%d/%d]", asInt(task["nb_completed_subtasks"]), total)
+	}
+	return badge
+}
+
+func (kc *kanboardClient) renderBoardHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectId, err := request.RequireInt("project_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	layout := request.GetString("layout", "sections")
+	if layout != "sections" && layout != "table" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported layout '%s' (expected sections or table)", layout)), nil
+	}
+	swimlaneFilter := request.GetString("swimlane", "")
+	tagFilter := strings.ToLower(request.GetString("tag", ""))
+
+	assigneeID := -1
+	if assignee := request.GetString("assignee", ""); assignee != "" {
+		assigneeID, err = kc.resolveUserID(ctx, assignee)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	swimlanes, err := kc.fetchBoard(ctx, projectId)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	counts := columnTaskCounts(swimlanes)
+
+	var tagged map[int]bool
+	if tagFilter != "" {
+		var taskIDs []int
+		for _, swimlane := range swimlanes {
+			for _, column := range swimlane.Columns {
+				for _, task := range column.Tasks {
+					taskIDs = append(taskIDs, asInt(task["id"]))
+				}
+			}
+		}
+		matches := make([]bool, len(taskIDs))
+		errs := make([]error, len(taskIDs))
+		forEachConcurrent(len(taskIDs), 8, func(i int) {
+			result, err := kc.callKanboardAPI(ctx, "getTaskTags", map[string]int{"task_id": taskIDs[i]})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for _, tag := range asMap(result) {
+				if strings.ToLower(asString(tag)) == tagFilter {
+					matches[i] = true
+				}
+			}
+		})
+		tagged = map[int]bool{}
+		for i, taskID := range taskIDs {
+			if errs[i] != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to get tags of task %d: %v", taskID, errs[i])), nil
+			}
+			tagged[taskID] = matches[i]
+		}
+	}
+
+	var inSprint map[int]bool
+	if sprintID := request.GetInt("sprint_id", 0); sprintID != 0 {
+		sprint, err := kc.getSprint(ctx, sprintID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		members, err := kc.sprintTasks(ctx, sprint)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		inSprint = map[int]bool{}
+		for _, task := range members {
+			inSprint[asInt(task["id"])] = true
+		}
+	}
+
+	keepTask := func(task map[string]interface{}) bool {
+		if inSprint != nil && !inSprint[asInt(task["id"])] {
+			return false
+		}
+		if assigneeID >= 0 && asInt(task["owner_id"]) != assigneeID {
+			return false
+		}
+		if tagged != nil && !tagged[asInt(task["id"])] {
+			return false
+		}
+		return true
+	}
+
+	now := time.Now().In(kc.location(ctx))
+	var sb strings.Builder
+
+	if len(swimlanes) > 0 {
+		sb.WriteString("**WIP:** ")
+		var parts []string
+		for _, column := range swimlanes[0].Columns {
+			parts = append(parts, fmt.Sprintf("%s %s", column.Title, wipLabel(counts[column.ID], column.TaskLimit)))
+		}
+		sb.WriteString(strings.Join(parts, " · ") + "\n")
+	}
+
+	for _, swimlane := range swimlanes {
+		if swimlaneFilter != "" && !strings.EqualFold(swimlane.Name, swimlaneFilter) {
+			continue
+		}
+		if len(swimlanes) > 1 || swimlaneFilter != "" {
+			fmt.Fprintf(&sb, "\n## %s\n", swimlane.Name)
+		}
+
+		filtered := make([][]map[string]interface{}, len(swimlane.Columns))
+		for i, column := range swimlane.Columns {
+			for _, task := range column.Tasks {
+				if keepTask(task) {
+					filtered[i] = append(filtered[i], task)
+				}
+			}
+		}
+
+		switch layout {
+		case "table":
+			headers := make([]string, len(swimlane.Columns))
+			rows := 0
+			for i, column := range swimlane.Columns {
+				headers[i] = fmt.Sprintf("%s (%s)", markdownCell(column.Title), wipLabel(counts[column.ID], column.TaskLimit))
+				if len(filtered[i]) > rows {
+					rows = len(filtered[i])
+				}
+			}
+			sb.WriteString("\n| " + strings.Join(headers, " | ") + " |\n")
+			sb.WriteString("|" + strings.Repeat("---|", len(headers)) + "\n")
+			for row := 0; row < rows; row++ {
+				cells := make([]string, len(swimlane.Columns))
+				for i := range swimlane.Columns {
+					if row < len(filtered[i]) {
+						cells[i] = markdownCell(taskBadge(filtered[i][row], now))
+					}
+				}
+				sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+			}
+		default:
+			for i, column := range swimlane.Columns {
+				fmt.Fprintf(&sb, "\n### %s (%s)\n", column.Title, wipLabel(counts[column.ID], column.TaskLimit))
+				if len(filtered[i]) == 0 {
+					sb.WriteString("_empty_\n")
+					continue
+				}
+				for _, task := range filtered[i] {
+					sb.WriteString("- " + taskBadge(task, now) + "\n")
+				}
+			}
+		}
+	}
+	return mcp.NewToolResultText(strings.TrimRight(sb.String(), "\n")), nil
+}
+
+// columnTransition is one task.move.column event.
+type columnTransition struct {
+	At   time.Time
+	From int
+	To   int
+}
+
+// taskFlow is the history of one task reconstructed from project activity events.
+type taskFlow struct {
+	TaskID      int
+	Title       string
+	Created     time.Time
+	Started     time.Time
+	Closed      time.Time
+	Transitions []columnTransition
+	Snapshot    map[string]interface{}
+}
+
+// fetchProjectEvents returns a project's activity events, oldest first.
+// Kanboard only keeps a limited activity history, so old events may be missing.
+func (kc *kanboardClient) fetchProjectEvents(ctx context.Context, projectID int) ([]map[string]interface{}, error) {
+	result, err := kc.callKanboardAPI(ctx, "getProjectActivities", map[string]interface{}{"project_ids": []int{projectID}})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get project activities: %v", err)
+	}
+	var events []map[string]interface{}
+	for _, item := range asList(result) {
+		events = append(events, asMap(item))
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return asInt(events[i]["date_creation"]) < asInt(events[j]["date_creation"])
+	})
+	return events, nil
+}
+
+// historyWarning warns when a project's activity history starts after from. Kanboard's
+// getProjectActivities only returns the latest events of a project (about 50), so whatever
+// happened before the oldest of them is missing from the reports built on it.
+func historyWarning(events []map[string]interface{}, from time.Time, loc *time.Location) string {
+	if len(events) == 0 {
+		return ""
+	}
+	earliest := asInt(events[0]["date_creation"])
+	if int64(earliest) <= from.Unix() {
+		return ""
+	}
+	return fmt.Sprintf("history starts at %s: Kanboard only returns the latest activity events, so earlier changes are missing", formatTimestamp(earliest, loc))
+}
+
+// historyStart moves a range start that wasn't given explicitly up to the oldest available
+// activity event, so a default range never reaches back further than the history. An
+// explicit "from" is kept, and historyWarning flags the gap instead.
+func historyStart(request mcp.CallToolRequest, events []map[string]interface{}, from, to time.Time) time.Time {
+	if request.GetString("from", "") != "" || len(events) == 0 {
+		return from
+	}
+	earliest := time.Unix(int64(asInt(events[0]["date_creation"])), 0).In(from.Location())
+	if earliest.After(from) && earliest.Before(to) {
+		return earliest
+	}
+	return from
+}
+
+// eventField looks a field up on an event, then in its "changes" and "task" payloads.
+func eventField(event map[string]interface{}, key string) interface{} {
+	if value, ok := event[key]; ok {
+		return value
+	}
+	if value, ok := asMap(event["changes"])[key]; ok {
+		return value
+	}
+	return asMap(event["task"])[key]
+}
+
+// buildTaskFlows replays task.create, task.move.column, task.close and task.open events.
+// Task snapshots embedded in the events fill in creation/start/completion dates when the
+// matching events are older than the available history.
+func buildTaskFlows(events []map[string]interface{}) map[int]*taskFlow {
+	flows := map[int]*taskFlow{}
+	for _, event := range events {
+		taskID := asInt(event["task_id"])
+		if taskID == 0 {
+			continue
+		}
+		flow, ok := flows[taskID]
+		if !ok {
+			flow = &taskFlow{TaskID: taskID}
+			flows[taskID] = flow
+		}
+		at := time.Unix(int64(asInt(event["date_creation"])), 0)
+		if snapshot := asMap(event["task"]); len(snapshot) > 0 {
+			flow.Snapshot = snapshot
+			flow.Title = asString(snapshot["title"])
+			if flow.Created.IsZero() {
+				if created := asInt(snapshot["date_creation"]); created > 0 {
+					flow.Created = time.Unix(int64(created), 0)
+				}
+			}
+			if flow.Started.IsZero() {
+				if started := asInt(snapshot["date_started"]); started > 0 {
+					flow.Started = time.Unix(int64(started), 0)
+				}
+			}
+		}
+
+		switch asString(event["event_name"]) {
+		case "task.create":
+			flow.Created = at
+		case "task.move.column":
+			from := asInt(eventField(event, "src_column_id"))
+			to := asInt(eventField(event, "dst_column_id"))
+			if to == 0 {
+				to = asInt(asMap(event["task"])["column_id"])
+			}
+			flow.Transitions = append(flow.Transitions, columnTransition{At: at, From: from, To: to})
+		case "task.close":
+			flow.Closed = at
+		case "task.open":
+			flow.Closed = time.Time{}
+		}
+	}
+	return flows
+}
+
+// cycleStart returns when work on a task started: its first move into a column at or
+// after startPosition, falling back to the task's date_started.
+func (flow *taskFlow) cycleStart(columnPositions map[int]int, startPosition int) time.Time {
+	for _, transition := range flow.Transitions {
+		if columnPositions[transition.To] >= startPosition {
+			return transition.At
+		}
+	}
+	return flow.Started
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// durationStats summarizes a set of durations expressed in days.
+type durationStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean_days"`
+	P50   float64 `json:"p50_days"`
+	P85   float64 `json:"p85_days"`
+	P95   float64 `json:"p95_days"`
+	Max   float64 `json:"max_days"`
+}
+
+func newDurationStats(days []float64) durationStats {
+	if len(days) == 0 {
+		return durationStats{}
+	}
+	sorted := append([]float64(nil), days...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, d := range sorted {
+		sum += d
+	}
+	return durationStats{
+		Count: len(sorted),
+		Mean:  roundTo(sum/float64(len(sorted)), 2),
+		P50:   roundTo(percentile(sorted, 50), 2),
+		P85:   roundTo(percentile(sorted, 85), 2),
+		P95:   roundTo(percentile(sorted, 95), 2),
+		Max:   roundTo(sorted[len(sorted)-1], 2),
+	}
+}
+
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
+
+// isoWeek labels the ISO week of t, e.g. "2024-W05".
+func isoWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// flowTaskMetric is the lead/cycle time of one closed task.
+type flowTaskMetric struct {
+	TaskID    int      `json:"task_id"`
+	Title     string   `json:"title"`
+	Group     string   `json:"group,omitempty"`
+	Closed    string   `json:"closed"`
+	LeadDays  float64  `json:"lead_time_days"`
+	CycleDays *float64 `json:"cycle_time_days,omitempty"`
+}
+
+// flowGroupMetrics are the metrics of one group of tasks.
+type flowGroupMetrics struct {
+	Group      string         `json:"group,omitempty"`
+	Closed     int            `json:"closed_tasks"`
+	LeadTime   durationStats  `json:"lead_time"`
+	CycleTime  durationStats  `json:"cycle_time"`
+	Throughput map[string]int `json:"weekly_throughput"`
+}
+
+// dateRangeArgs parses the "from" and "to" arguments in loc, defaulting to the last
+// defaultDays days. A date-only "to" covers that whole day.
+func dateRangeArgs(request mcp.CallToolRequest, loc *time.Location, defaultDays int) (time.Time, time.Time, error) {
+	now := time.Now().In(loc)
+	to := now
+	if value := request.GetString("to", ""); value != "" {
+		parsed, hasTime, err := parseDateExpr(value, now, loc, nil)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to: %v", err)
+		}
+		to = parsed
+		if !hasTime {
+			to = to.AddDate(0, 0, 1).Add(-time.Second)
+		}
+	}
+	from := to.AddDate(0, 0, -defaultDays)
+	if value := request.GetString("from", ""); value != "" {
+		parsed, _, err := parseDateExpr(value, now, loc, nil)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from: %v", err)
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
+
+// columnPositions returns the position of every column of a project by column ID.
+func (kc *kanboardClient) columnPositions(ctx context.Context, projectID int) (map[int]int, map[int]string, error) {
+	result, err := kc.callKanboardAPICached(ctx, "getColumns", map[string]int{"project_id": projectID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get columns: %v", err)
+	}
+	positions := map[int]int{}
+	titles := map[int]string{}
+	for _, item := range asList(result) {
+		column := asMap(item)
+		positions[asInt(column["id"])] = asInt(column["position"])
+		titles[asInt(column["id"])] = asString(column["title"])
+	}
+	return positions, titles, nil
+}
+
+func (kc *kanboardClient) flowMetricsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectId, err := request.RequireInt("project_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	from, to, err := dateRangeArgs(request, kc.location(ctx), 30)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	groupBy := request.GetString("group_by", "")
+	groupFields := map[string]string{"swimlane": "swimlane", "category": "category", "assignee": "assignee"}
+	if _, ok := groupFields[groupBy]; groupBy != "" && !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported group_by '%s' (expected swimlane, category or assignee)", groupBy)), nil
+	}
+
+	positions, titles, err := kc.columnPositions(ctx, projectId)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	startPosition := 2
+	if startColumn := request.GetString("start_column", ""); startColumn != "" {
+		startPosition = 0
+		for columnID, title := range titles {
+			if strings.EqualFold(title, startColumn) {
+				startPosition = positions[columnID]
+			}
+		}
+		if startPosition == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Column '%s' not found", startColumn)), nil
+		}
+	}
+
+	events, err := kc.fetchProjectEvents(ctx, projectId)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	from = historyStart(request, events, from, to)
+	flows := buildTaskFlows(events)
+
+	resolver := kc.newNameResolver(ctx)
+	loc := kc.location(ctx)
+	var tasks []flowTaskMetric
+	leadByGroup := map[string][]float64{}
+	cycleByGroup := map[string][]float64{}
+	throughputByGroup := map[string]map[string]int{}
+	var groupOrder []string
+
+	for _, flow := range flows {
+		if flow.Closed.IsZero() || flow.Closed.Before(from) || flow.Closed.After(to) || flow.Created.IsZero() {
+			continue
+		}
+		group := ""
+		if groupBy != "" {
+			group = asString(resolver.normalize(ctx, flow.Snapshot)[groupFields[groupBy]])
+			if group == "" {
+				group = "(none)"
+			}
+		}
+		if _, ok := throughputByGroup[group]; !ok {
+			throughputByGroup[group] = map[string]int{}
+			groupOrder = append(groupOrder, group)
+		}
+
+		metric := flowTaskMetric{
+			TaskID:   flow.TaskID,
+			Title:    flow.Title,
+			Group:    group,
+			Closed:   formatTimestamp(int(flow.Closed.Unix()), loc),
+			LeadDays: roundTo(flow.Closed.Sub(flow.Created).Hours()/24, 2),
+		}
+		leadByGroup[group] = append(leadByGroup[group], metric.LeadDays)
+		if start := flow.cycleStart(positions, startPosition); !start.IsZero() && !start.After(flow.Closed) {
+			cycle := roundTo(flow.Closed.Sub(start).Hours()/24, 2)
+			metric.CycleDays = &cycle
+			cycleByGroup[group] = append(cycleByGroup[group], cycle)
+		}
+		throughputByGroup[group][isoWeek(flow.Closed.In(loc))]++
+		tasks = append(tasks, metric)
+	}
+	sort.Strings(groupOrder)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Closed < tasks[j].Closed })
+
+	var weeks []string
+	for week := from; !week.After(to); week = week.AddDate(0, 0, 7) {
+		weeks = append(weeks, isoWeek(week))
+	}
+	if last := isoWeek(to); len(weeks) == 0 || weeks[len(weeks)-1] != last {
+		weeks = append(weeks, last)
+	}
+
+	var groups []flowGroupMetrics
+	for _, group := range groupOrder {
+		throughput := map[string]int{}
+		for _, week := range weeks {
+			throughput[week] = throughputByGroup[group][week]
+		}
+		groups = append(groups, flowGroupMetrics{
+			Group:      group,
+			Closed:     len(leadByGroup[group]),
+			LeadTime:   newDurationStats(leadByGroup[group]),
+			CycleTime:  newDurationStats(cycleByGroup[group]),
+			Throughput: throughput,
+		})
+	}
+
+	earliest := ""
+	if len(events) > 0 {
+		earliest = formatTimestamp(asInt(events[0]["date_creation"]), loc)
+	}
+	report := map[string]interface{}{
+		"project_id":        projectId,
+		"from":              formatTimestamp(int(from.Unix()), loc),
+		"to":                formatTimestamp(int(to.Unix()), loc),
+		"events_analyzed":   len(events),
+		"earliest_event":    earliest,
+		"cycle_start_after": startPosition,
+		"groups":            groups,
+	}
+	if warning := historyWarning(events, from, loc); warning != "" {
+		report["warning"] = warning
+	}
+	if request.GetBool("include_tasks", false) {
+		report["tasks"] = tasks
+	}
+
+	if request.GetString("format", "json") == "markdown" {
+		return mcp.NewToolResultText(renderFlowMetricsMarkdown(report, groups, weeks, tasks, request.GetBool("include_tasks", false))), nil
+	}
+	resultBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+func renderFlowMetricsMarkdown(report map[string]interface{}, groups []flowGroupMetrics, weeks []string, tasks []flowTaskMetric, includeTasks bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Flow metrics for project %s\n\n", asString(report["project_id"]))
+	fmt.Fprintf(&sb, "%s → %s, %s events analyzed (earliest %s)\n", asString(report["from"]), asString(report["to"]), asString(report["events_analyzed"]), asString(report["earliest_event"]))
+	if warning := asString(report["warning"]); warning != "" {
+		fmt.Fprintf(&sb, "\n> ⚠️ %s\n", warning)
+	}
+
+	if len(groups) == 0 {
+		sb.WriteString("\n_No tasks closed in this period._\n")
+		return sb.String()
+	}
+	for _, group := range groups {
+		if group.Group != "" {
+			fmt.Fprintf(&sb, "\n## %s\n", group.Group)
+		}
+		fmt.Fprintf(&sb, "\n| Metric | Count | Mean | P50 | P85 | P95 | Max |\n|---|---|---|---|---|---|---|\n")
+		for _, row := range []struct {
+			label string
+			stats durationStats
+		}{{"Lead time (days)", group.LeadTime}, {"Cycle time (days)", group.CycleTime}} {
+			fmt.Fprintf(&sb, "| %s | %d | %.2f | %.2f | %.2f | %.2f | %.2f |\n", row.label, row.stats.Count, row.stats.Mean, row.stats.P50, row.stats.P85, row.stats.P95, row.stats.Max)
+		}
+		sb.WriteString("\nWeekly throughput: ")
+		var parts []string
+		for _, week := range weeks {
+			parts = append(parts, fmt.Sprintf("%s %d", week, group.Throughput[week]))
+		}
+		sb.WriteString(strings.Join(parts, " · ") + "\n")
+	}
+
+	if includeTasks && len(tasks) > 0 {
+		sb.WriteString("\n## Tasks\n\n| Task | Closed | Lead (days) | Cycle (days) |\n|---|---|---|---|\n")
+		for _, task := range tasks {
+			cycle := "-"
+			if task.CycleDays != nil {
+				cycle = fmt.Sprintf("%.2f", *task.CycleDays)
+			}
+			fmt.Fprintf(&sb, "| #%d %s | %s | %.2f | %s |\n", task.TaskID, markdownCell(task.Title), task.Closed, task.LeadDays, cycle)
+		}
+	}
+	return sb.String()
+}
+
+// columnAt returns the column a task was in at time t and when it entered it, replaying
+// its transitions. currentColumn is the task's column today, created its creation time and
+// moved its last column change (date_moved). ok is false when the history can't tell: the
+// task changed column after t, but that move is older than the available events.
+func (flow *taskFlow) columnAt(t time.Time, currentColumn int, created, moved time.Time) (column int, since time.Time, ok bool) {
+	var transitions []columnTransition
+	if flow != nil {
+		transitions = flow.Transitions
+	}
+	since = created
+	for _, transition := range transitions {
+		if transition.At.After(t) {
+			if column == 0 {
+				// The first move after t left the column the task was in at t.
+				column = transition.From
+			}
+			return column, since, column != 0
+		}
+		column, since = transition.To, transition.At
+	}
+	if column != 0 {
+		return column, since, true
+	}
+	if moved.After(t) {
+		return 0, time.Time{}, false
+	}
+	if moved.After(created) {
+		since = moved
+	}
+	return currentColumn, since, true
+}
+
+// cfdBottleneck flags a column where work gets stuck.
+type cfdBottleneck struct {
+	Column string `json:"column"`
+	Reason string `json:"reason"`
+}
+
+func (kc *kanboardClient) cumulativeFlowHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectId, err := request.RequireInt("project_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	from, to, err := dateRangeArgs(request, kc.location(ctx), 14)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	format := request.GetString("format", "json")
+	if format != "json" && format != "csv" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported format '%s' (expected json or csv)", format)), nil
+	}
+
+	columnsResult, err := kc.callKanboardAPICached(ctx, "getColumns", map[string]int{"project_id": projectId})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get columns: %v", err)), nil
+	}
+	var columns []map[string]interface{}
+	for _, item := range asList(columnsResult) {
+		columns = append(columns, asMap(item))
+	}
+	sort.SliceStable(columns, func(i, j int) bool { return asInt(columns[i]["position"]) < asInt(columns[j]["position"]) })
+
+	var tasks []map[string]interface{}
+	for _, statusID := range []int{1, 0} {
+		result, err := kc.callKanboardAPI(ctx, "getAllTasks", map[string]int{"project_id": projectId, "status_id": statusID})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get tasks: %v", err)), nil
+		}
+		for _, item := range asList(result) {
+			tasks = append(tasks, asMap(item))
+		}
+	}
+
+	events, err := kc.fetchProjectEvents(ctx, projectId)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	from = historyStart(request, events, from, to)
+	flows := buildTaskFlows(events)
+
+	// Counts are keyed by column ID, since column titles need not be unique. Unknown counts
+	// the open tasks whose column on that day is older than the available history.
+	type cfdDay struct {
+		Date    string      `json:"date"`
+		Counts  map[int]int `json:"counts"`
+		Closed  int         `json:"closed"`
+		Unknown int         `json:"unknown"`
+	}
+	var days []cfdDay
+	// Per column: daily task count and mean age (days) of the tasks waiting in it.
+	dailyCounts := map[int][]int{}
+	dailyAges := map[int][]float64{}
+
+	firstDay := time.Date(from.Year(), from.Month(), from.Day(), 23, 59, 59, 0, from.Location())
+	lastDay := time.Date(to.Year(), to.Month(), to.Day(), 23, 59, 59, 0, to.Location())
+	for day := firstDay; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		counts := map[int]int{}
+		ageSums := map[int]float64{}
+		closed, unknown := 0, 0
+		for _, task := range tasks {
+			created := time.Unix(int64(asInt(task["date_creation"])), 0)
+			if created.After(day) {
+				continue
+			}
+			if asInt(task["is_active"]) == 0 {
+				if completed := asInt(task["date_completed"]); completed > 0 && int64(completed) <= day.Unix() {
+					closed++
+					continue
+				}
+			}
+			moved := time.Unix(int64(asInt(task["date_moved"])), 0)
+			column, since, ok := flows[asInt(task["id"])].columnAt(day, asInt(task["column_id"]), created, moved)
+			if !ok {
+				unknown++
+				continue
+			}
+			counts[column]++
+			ageSums[column] += day.Sub(since).Hours() / 24
+		}
+
+		row := cfdDay{Date: day.Format("2006-01-02"), Counts: map[int]int{}, Closed: closed, Unknown: unknown}
+		for _, column := range columns {
+			columnID := asInt(column["id"])
+			row.Counts[columnID] = counts[columnID]
+			dailyCounts[columnID] = append(dailyCounts[columnID], counts[columnID])
+			age := 0.0
+			if counts[columnID] > 0 {
+				age = ageSums[columnID] / float64(counts[columnID])
+			}
+			dailyAges[columnID] = append(dailyAges[columnID], age)
+		}
+		days = append(days, row)
+	}
+
+	bottlenecks := []cfdBottleneck{}
+	for i, column := range columns {
+		columnID := asInt(column["id"])
+		title := asString(column["title"])
+		counts := dailyCounts[columnID]
+		if limit := asInt(column["task_limit"]); limit > 0 {
+			over := 0
+			for _, count := range counts {
+				if count > limit {
+					over++
+				}
+			}
+			if len(counts) > 0 && float64(over)/float64(len(counts)) >= 0.3 {
+				bottlenecks = append(bottlenecks, cfdBottleneck{Column: title, Reason: fmt.Sprintf("WIP exceeded the limit of %d on %d of %d days", limit, over, len(counts))})
+			}
+		}
+		// The last column is where finished work piles up, so growth there is expected.
+		if i == len(columns)-1 || len(counts) < 4 {
+			continue
+		}
+		half := len(counts) / 2
+		firstAge, secondAge := meanFloat(dailyAges[columnID][:half]), meanFloat(dailyAges[columnID][half:])
+		if secondAge > 1 && secondAge > firstAge*1.25 {
+			bottlenecks = append(bottlenecks, cfdBottleneck{Column: title, Reason: fmt.Sprintf("queue time growing: tasks waited %.1f days on average in the second half of the period vs %.1f before", secondAge, firstAge)})
+		}
+		firstCount, secondCount := meanInt(counts[:half]), meanInt(counts[half:])
+		if secondCount >= 2 && secondCount > firstCount*1.5 {
+			bottlenecks = append(bottlenecks, cfdBottleneck{Column: title, Reason: fmt.Sprintf("work accumulating: %.1f tasks on average in the second half of the period vs %.1f before", secondCount, firstCount)})
+		}
+	}
+
+	if format == "csv" {
+		var sb strings.Builder
+		writer := csv.NewWriter(&sb)
+		header := []string{"date"}
+		for _, column := range columns {
+			header = append(header, asString(column["title"]))
+		}
+		writer.Write(append(header, "closed", "unknown"))
+		for _, day := range days {
+			record := []string{day.Date}
+			for _, column := range columns {
+				record = append(record, strconv.Itoa(day.Counts[asInt(column["id"])]))
+			}
+			writer.Write(append(record, strconv.Itoa(day.Closed), strconv.Itoa(day.Unknown)))
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to write CSV: %v", err)), nil
+		}
+
+		result := mcp.NewToolResultText(sb.String())
+		if warning := historyWarning(events, from, kc.location(ctx)); warning != "" {
+			result.Content = append(result.Content, mcp.NewTextContent("Warning: "+warning))
+		}
+		if len(bottlenecks) > 0 {
+			var notes []string
+			for _, bottleneck := range bottlenecks {
+				notes = append(notes, fmt.Sprintf("- %s: %s", bottleneck.Column, bottleneck.Reason))
+			}
+			result.Content = append(result.Content, mcp.NewTextContent("Bottlenecks:\n"+strings.Join(notes, "\n")))
+		}
+		return result, nil
+	}
+
+	type cfdColumn struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	cfdColumns := make([]cfdColumn, len(columns))
+	for i, column := range columns {
+		cfdColumns[i] = cfdColumn{ID: asInt(column["id"]), Title: asString(column["title"])}
+	}
+	earliest := ""
+	if len(events) > 0 {
+		earliest = formatTimestamp(asInt(events[0]["date_creation"]), kc.location(ctx))
+	}
+	report := map[string]interface{}{
+		"project_id":     projectId,
+		"columns":        cfdColumns,
+		"days":           days,
+		"bottlenecks":    bottlenecks,
+		"earliest_event": earliest,
+	}
+	if warning := historyWarning(events, from, kc.location(ctx)); warning != "" {
+		report["warning"] = warning
+	}
+	resultBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+func meanFloat(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+func meanInt(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, value := range values {
+		sum += value
+	}
+	return float64(sum) / float64(len(values))
+}
+
+// sprintMetadataKey is the task metadata key that records which sprint a task belongs to.
+const sprintMetadataKey = "sprint_id"
+
+// sprintInfo is the subset of a ScrumSprint sprint used by the sprint reports.
+type sprintInfo struct {
+	ID          int
+	ProjectID   int
+	Name        string
+	Start       time.Time
+	End         time.Time
+	IsActive    bool
+	IsCompleted bool
+	Raw         map[string]interface{}
+}
+
+// parseSprintDate accepts the unix timestamps or date strings the ScrumSprint plugin may
+// return; date strings are read in loc.
+func parseSprintDate(value interface{}, loc *time.Location) time.Time {
+	if ts := asInt(value); ts > 100000 {
+		return time.Unix(int64(ts), 0).In(loc)
+	}
+	parsed, _, err := parseDateExpr(asString(value), time.Now(), loc, nil)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+func newSprintInfo(raw map[string]interface{}, loc *time.Location) sprintInfo {
+	sprint := sprintInfo{
+		ID:          asInt(raw["id"]),
+		ProjectID:   asInt(raw["project_id"]),
+		Name:        asString(raw["name"]),
+		IsActive:    asInt(raw["is_active"]) == 1,
+		IsCompleted: asInt(raw["is_completed"]) == 1,
+		Raw:         raw,
+	}
+	for _, key := range []string{"start_date", "date_start"} {
+		if value, ok := raw[key]; ok {
+			sprint.Start = parseSprintDate(value, loc)
+			break
+		}
+	}
+	for _, key := range []string{"end_date", "date_end"} {
+		if value, ok := raw[key]; ok {
+			sprint.End = parseSprintDate(value, loc)
+			break
+		}
+	}
+	if !sprint.End.IsZero() {
+		sprint.End = time.Date(sprint.End.Year(), sprint.End.Month(), sprint.End.Day(), 23, 59, 59, 0, sprint.End.Location())
+	}
+	return sprint
+}
+
+func (kc *kanboardClient) getSprint(ctx context.Context, sprintID int) (sprintInfo, error) {
+	result, err := kc.callKanboardAPI(ctx, "getSprintById", map[string]int{"sprint_id": sprintID})
+	if err != nil {
+		return sprintInfo{}, fmt.Errorf("Failed to get sprint by ID: %v", err)
+	}
+	raw := asMap(result)
+	if len(raw) == 0 {
+		return sprintInfo{}, fmt.Errorf("Sprint %d not found", sprintID)
+	}
+	return newSprintInfo(raw, kc.location(ctx)), nil
+}
+
+// projectSprints returns a project's sprints ordered by start date.
+func (kc *kanboardClient) projectSprints(ctx context.Context, projectID int) ([]sprintInfo, error) {
+	result, err := kc.callKanboardAPI(ctx, "getAllSprintsByProject", map[string]string{"project_id": strconv.Itoa(projectID)})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get all sprints by project: %v", err)
+	}
+	loc := kc.location(ctx)
+	var sprints []sprintInfo
+	for _, item := range asList(result) {
+		sprints = append(sprints, newSprintInfo(asMap(item), loc))
+	}
+	sort.SliceStable(sprints, func(i, j int) bool { return sprints[i].Start.Before(sprints[j].Start) })
+	return sprints, nil
+}
+
+// projectTasks returns every open and closed task of a project.
+func (kc *kanboardClient) projectTasks(ctx context.Context, projectID int) ([]map[string]interface{}, error) {
+	var tasks []map[string]interface{}
+	for _, statusID := range []int{1, 0} {
+		result, err := kc.callKanboardAPI(ctx, "getAllTasks", map[string]int{"project_id": projectID, "status_id": statusID})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get tasks: %v", err)
+		}
+		for _, item := range asList(result) {
+			tasks = append(tasks, asMap(item))
+		}
+	}
+	return tasks, nil
+}
+
+// sprintAssignments returns the sprint ID recorded in each task's metadata (0 when unset).
+// A failed lookup fails the whole call rather than reading as "no sprint".
+func (kc *kanboardClient) sprintAssignments(ctx context.Context, tasks []map[string]interface{}) (map[int]int, error) {
+	sprintIDs := make([]int, len(tasks))
+	errs := make([]error, len(tasks))
+	forEachConcurrent(len(tasks), 8, func(i int) {
+		result, err := kc.callKanboardAPI(ctx, "getTaskMetadataByName", []interface{}{asInt(tasks[i]["id"]), sprintMetadataKey})
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		sprintIDs[i] = asInt(result)
+	})
+	assignments := map[int]int{}
+	for i, task := range tasks {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("Failed to get the sprint of task %d: %v", asInt(task["id"]), errs[i])
+		}
+		assignments[asInt(task["id"])] = sprintIDs[i]
+	}
+	return assignments, nil
+}
+
+// sprintMembers returns the tasks whose sprint metadata names the sprint. setTaskSprint
+// writes that metadata whether or not the ScrumSprint plugin is installed, so it is the only
+// source of membership: due dates never pull a task into a sprint.
+func sprintMembers(sprint sprintInfo, tasks []map[string]interface{}, assignments map[int]int) []map[string]interface{} {
+	var members []map[string]interface{}
+	for _, task := range tasks {
+		if assignments[asInt(task["id"])] == sprint.ID {
+			members = append(members, task)
+		}
+	}
+	return members
+}
+
+// taskClosedAt returns when a task was closed, preferring the last close event over date_completed.
+func taskClosedAt(task map[string]interface{}, flows map[int]*taskFlow) time.Time {
+	if asInt(task["is_active"]) == 1 {
+		return time.Time{}
+	}
+	if flow := flows[asInt(task["id"])]; flow != nil && !flow.Closed.IsZero() {
+		return flow.Closed
+	}
+	if completed := asInt(task["date_completed"]); completed > 0 {
+		return time.Unix(int64(completed), 0)
+	}
+	return time.Time{}
+}
+
+// taskPoints returns a task's size in the chosen unit: complexity score or estimated hours.
+func taskPoints(task map[string]interface{}, unit string) float64 {
+	if unit == "hours" {
+		return asFloat(task["time_estimated"])
+	}
+	return asFloat(task["score"])
+}
+
+// sprintSummary holds the committed and completed points of one sprint.
+type sprintSummary struct {
+	SprintID  int     `json:"sprint_id"`
+	Name      string  `json:"name"`
+	Start     string  `json:"start"`
+	End       string  `json:"end"`
+	Committed float64 `json:"committed"`
+	Added     float64 `json:"added"`
+	Completed float64 `json:"completed"`
+}
+
+func summarizeSprint(sprint sprintInfo, members []map[string]interface{}, flows map[int]*taskFlow, unit string) sprintSummary {
+	summary := sprintSummary{
+		SprintID: sprint.ID,
+		Name:     sprint.Name,
+		Start:    sprint.Start.Format("2006-01-02"),
+		End:      sprint.End.Format("2006-01-02"),
+	}
+	committedBy := sprint.Start.Add(24 * time.Hour)
+	for _, task := range members {
+		points := taskPoints(task, unit)
+		if int64(asInt(task["date_creation"])) <= committedBy.Unix() {
+			summary.Committed += points
+		} else {
+			summary.Added += points
+		}
+		if closed := taskClosedAt(task, flows); !closed.IsZero() && !closed.After(sprint.End) {
+			summary.Completed += points
+		}
+	}
+	return summary
+}
+
+func (kc *kanboardClient) sprintReportHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sprintID, err := request.RequireInt("sprint_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	unit := request.GetString("unit", "score")
+	if unit != "score" && unit != "hours" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported unit '%s' (expected score or hours)", unit)), nil
+	}
+	velocitySprints := request.GetInt("velocity_sprints", 3)
+	if velocitySprints < 1 {
+		return mcp.NewToolResultError("velocity_sprints must be at least 1"), nil
+	}
+
+	sprint, err := kc.getSprint(ctx, sprintID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if sprint.Start.IsZero() || sprint.End.IsZero() {
+		return mcp.NewToolResultError(fmt.Sprintf("Sprint %d has no start or end date", sprintID)), nil
+	}
+
+	tasks, err := kc.projectTasks(ctx, sprint.ProjectID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	events, err := kc.fetchProjectEvents(ctx, sprint.ProjectID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	flows := buildTaskFlows(events)
+	assignments, err := kc.sprintAssignments(ctx, tasks)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	members := sprintMembers(sprint, tasks, assignments)
+	summary := summarizeSprint(sprint, members, flows, unit)
+
+	// Burndown: remaining points at the end of each sprint day, next to the ideal line.
+	type burndownPoint struct {
+		Date      string  `json:"date"`
+		Remaining float64 `json:"remaining"`
+		Ideal     float64 `json:"ideal"`
+	}
+	var burndown []burndownPoint
+	lastDay := sprint.End
+	if now := time.Now(); now.Before(lastDay) {
+		lastDay = now
+	}
+	totalDays := int(sprint.End.Sub(sprint.Start).Hours()/24) + 1
+	dayIndex := 0
+	for day := time.Date(sprint.Start.Year(), sprint.Start.Month(), sprint.Start.Day(), 23, 59, 59, 0, sprint.Start.Location()); !day.After(lastDay.Add(24*time.Hour - time.Second)); day = day.AddDate(0, 0, 1) {
+		remaining := 0.0
+		for _, task := range members {
+			if int64(asInt(task["date_creation"])) > day.Unix() {
+				continue
+			}
+			if closed := taskClosedAt(task, flows); !closed.IsZero() && !closed.After(day) {
+				continue
+			}
+			remaining += taskPoints(task, unit)
+		}
+		ideal := summary.Committed
+		if totalDays > 1 {
+			ideal = roundTo(summary.Committed*(1-float64(dayIndex)/float64(totalDays-1)), 2)
+		}
+		burndown = append(burndown, burndownPoint{Date: day.Format("2006-01-02"), Remaining: remaining, Ideal: ideal})
+		dayIndex++
+	}
+
+	var carryOver []map[string]interface{}
+	for _, task := range members {
+		if closed := taskClosedAt(task, flows); closed.IsZero() || closed.After(sprint.End) {
+			carryOver = append(carryOver, map[string]interface{}{
+				"id":     asInt(task["id"]),
+				"title":  asString(task["title"]),
+				"points": taskPoints(task, unit),
+			})
+		}
+	}
+
+	// Rolling velocity: the last completed sprints up to this one. A sprint that is still
+	// running (this one included) would drag the average down, so it is left out.
+	sprints, err := kc.projectSprints(ctx, sprint.ProjectID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var completed []sprintInfo
+	for _, candidate := range sprints {
+		if candidate.IsCompleted && !candidate.End.After(sprint.End) && candidate.ID != sprint.ID {
+			completed = append(completed, candidate)
+		}
+	}
+	if sprint.IsCompleted {
+		completed = append(completed, sprint)
+	}
+	if len(completed) > velocitySprints {
+		completed = completed[len(completed)-velocitySprints:]
+	}
+	history := []sprintSummary{}
+	velocity := 0.0
+	for _, candidate := range completed {
+		entry := summary
+		if candidate.ID != sprint.ID {
+			entry = summarizeSprint(candidate, sprintMembers(candidate, tasks, assignments), flows, unit)
+		}
+		history = append(history, entry)
+		velocity += entry.Completed
+	}
+	if len(history) > 0 {
+		velocity = roundTo(velocity/float64(len(history)), 2)
+	}
+
+	report := map[string]interface{}{
+		"sprint":           summary,
+		"unit":             unit,
+		"goal":             asString(sprint.Raw["goal"]),
+		"tasks":            len(members),
+		"burndown":         burndown,
+		"carry_over":       carryOver,
+		"velocity_history": history,
+		"rolling_velocity": velocity,
+	}
+
+	if request.GetString("format", "markdown") == "json" {
+		resultBytes, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(resultBytes)), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Sprint report: %s\n\n", sprint.Name)
+	fmt.Fprintf(&sb, "%s → %s · %d tasks · unit: %s\n", summary.Start, summary.End, len(members), unit)
+	if goal := asString(sprint.Raw["goal"]); goal != "" {
+		fmt.Fprintf(&sb, "\n**Goal:** %s\n", goal)
+	}
+	sb.WriteString("\n## Commitment\n\n")
+	fmt.Fprintf(&sb, "- Committed: %g\n- Added during sprint: %g\n- Completed: %g\n", summary.Committed, summary.Added, summary.Completed)
+	if planned := summary.Committed + summary.Added; planned > 0 {
+		fmt.Fprintf(&sb, "- Completion: %.0f%%\n", summary.Completed/planned*100)
+	}
+
+	sb.WriteString("\n## Burndown\n\n| Day | Remaining | Ideal |\n|---|---|---|\n")
+	for _, point := range burndown {
+		fmt.Fprintf(&sb, "| %s | %g | %g |\n", point.Date, point.Remaining, point.Ideal)
+	}
+
+	sb.WriteString("\n## Carry-over\n\n")
+	if len(carryOver) == 0 {
+		sb.WriteString("_Everything was finished._\n")
+	}
+	for _, task := range carryOver {
+		fmt.Fprintf(&sb, "- #%d %s (%g)\n", task["id"], task["title"], task["points"])
+	}
+
+	sb.WriteString("\n## Velocity\n\n")
+	if len(history) == 0 {
+		sb.WriteString("_No completed sprint yet._\n")
+		return mcp.NewToolResultText(sb.String()), nil
+	}
+	sb.WriteString("| Sprint | Committed | Completed |\n|---|---|---|\n")
+	for _, entry := range history {
+		fmt.Fprintf(&sb, "| %s | %g | %g |\n", markdownCell(entry.Name), entry.Committed+entry.Added, entry.Completed)
+	}
+	fmt.Fprintf(&sb, "\nRolling velocity over %d completed sprint(s): **%g**\n", len(history), velocity)
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// isMethodNotFound reports whether a JSON-RPC call failed because the server doesn't
+// provide the method, e.g. because an optional plugin isn't installed.
+func isMethodNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "code -32601")
+}
+
+// setTaskSprint moves a task from previousSprintID to sprintID (0 removes it from its sprint).
+// The ScrumSprint plugin is told through addTaskToSprint/removeTaskFromSprint when it provides
+// them; the sprint_id task metadata is always written so the sprint reports work either way.
+func (kc *kanboardClient) setTaskSprint(ctx context.Context, taskID, sprintID, previousSprintID int) (string, error) {
+	via := "metadata"
+	var err error
+	if sprintID != 0 {
+		_, err = kc.callKanboardAPI(ctx, "addTaskToSprint", map[string]int{"sprint_id": sprintID, "task_id": taskID})
+	}
+	if err == nil && previousSprintID != 0 && previousSprintID != sprintID {
+		_, err = kc.callKanboardAPI(ctx, "removeTaskFromSprint", map[string]int{"sprint_id": previousSprintID, "task_id": taskID})
+	}
+	if err == nil && (sprintID != 0 || previousSprintID != 0) {
+		via = "plugin"
+	} else if err != nil && !isMethodNotFound(err) {
+		return "", err
+	}
+
+	if sprintID != 0 {
+		_, err = kc.callKanboardAPI(ctx, "saveTaskMetadata", map[string]interface{}{
+			"task_id": taskID,
+			"values":  map[string]string{sprintMetadataKey: strconv.Itoa(sprintID)},
+		})
+	} else {
+		_, err = kc.callKanboardAPI(ctx, "removeTaskMetadata", []interface{}{taskID, sprintMetadataKey})
+	}
+	if err != nil {
+		return "", err
+	}
+	return via, nil
+}
+
+// sprintTasks returns the tasks of a sprint.
+func (kc *kanboardClient) sprintTasks(ctx context.Context, sprint sprintInfo) ([]map[string]interface{}, error) {
+	tasks, err := kc.projectTasks(ctx, sprint.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := kc.sprintAssignments(ctx, tasks)
+	if err != nil {
+		return nil, err
+	}
+	return sprintMembers(sprint, tasks, assignments), nil
+}
+
+// updateSprintMembership adds tasks to a sprint, or removes them from it when remove is set.
+func (kc *kanboardClient) updateSprintMembership(ctx context.Context, request mcp.CallToolRequest, remove bool) (*mcp.CallToolResult, error) {
+	sprintID, err := request.RequireInt("sprint_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	taskIDs, err := request.RequireIntSlice("task_ids")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(taskIDs) == 0 {
+		return mcp.NewToolResultError("At least one task ID is required"), nil
+	}
+	sprint, err := kc.getSprint(ctx, sprintID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	type membershipResult struct {
+		TaskID int    `json:"task_id"`
+		Via    string `json:"via,omitempty"`
+		Error  string `json:"error,omitempty"`
+	}
+	results := make([]membershipResult, len(taskIDs))
+	forEachConcurrent(len(taskIDs), 8, func(i int) {
+		results[i].TaskID = taskIDs[i]
+		task, err := kc.callKanboardAPI(ctx, "getTask", map[string]int{"task_id": taskIDs[i]})
+		if err != nil {
+			results[i].Error = err.Error()
+			return
+		}
+		if asInt(asMap(task)["project_id"]) != sprint.ProjectID {
+			results[i].Error = fmt.Sprintf("task %d does not belong to the sprint's project", taskIDs[i])
+			return
+		}
+		target, previous := sprintID, 0
+		if remove {
+			target, previous = 0, sprintID
+		}
+		results[i].Via, err = kc.setTaskSprint(ctx, taskIDs[i], target, previous)
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	})
+
+	resultBytes, err := json.MarshalIndent(map[string]interface{}{
+		"sprint_id": sprintID,
+		"tasks":     results,
+	}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+func (kc *kanboardClient) addTasksToSprintHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return kc.updateSprintMembership(ctx, request, false)
+}
+
+func (kc *kanboardClient) removeTasksFromSprintHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return kc.updateSprintMembership(ctx, request, true)
+}
+
+func (kc *kanboardClient) getSprintTasksHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sprintID, err := request.RequireInt("sprint_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sprint, err := kc.getSprint(ctx, sprintID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	members, err := kc.sprintTasks(ctx, sprint)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	items := make([]interface{}, len(members))
+	for i, task := range members {
+		items[i] = task
+	}
+	return kc.formatListResult(ctx, request, items, "task")
+}
+
+func (kc *kanboardClient) closeSprintHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sprintID, err := request.RequireInt("sprint_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	carryOver := request.GetString("carry_over", "next")
+	if carryOver != "next" && carryOver != "backlog" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported carry_over '%s' (expected next or backlog)", carryOver)), nil
+	}
+
+	sprint, err := kc.getSprint(ctx, sprintID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if sprint.IsCompleted {
+		return mcp.NewToolResultError(fmt.Sprintf("Sprint %d is already completed", sprintID)), nil
+	}
+	members, err := kc.sprintTasks(ctx, sprint)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	nextSprintID := request.GetInt("next_sprint_id", 0)
+	if carryOver == "next" && nextSprintID == 0 {
+		sprints, err := kc.projectSprints(ctx, sprint.ProjectID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, candidate := range sprints {
+			if candidate.ID != sprint.ID && !candidate.IsCompleted && candidate.Start.After(sprint.Start) {
+				nextSprintID = candidate.ID
+				break
+			}
+		}
+		if nextSprintID == 0 {
+			carryOver = "backlog"
+		}
+	}
+	if carryOver == "backlog" {
+		nextSprintID = 0
+	}
+
+	// Finished tasks keep this sprint so the closed sprint still reports them; unfinished
+	// ones move on.
+	var finished, moved []int
+	var failures []string
+	for _, task := range members {
+		taskID := asInt(task["id"])
+		if asInt(task["is_active"]) != 1 {
+			finished = append(finished, taskID)
+			continue
+		}
+		if _, err := kc.setTaskSprint(ctx, taskID, nextSprintID, sprintID); err != nil {
+			failures = append(failures, fmt.Sprintf("task %d: %v", taskID, err))
+		}
+		moved = append(moved, taskID)
+	}
+	if len(failures) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Sprint %d was left open because some tasks could not be updated: %s", sprintID, strings.Join(failures, "; "))), nil
+	}
+
+	if _, err := kc.callKanboardAPI(ctx, "updateSprint", map[string]interface{}{
+		"sprint_id":    sprintID,
+		"is_completed": true,
+		"is_active":    false,
+	}); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to update sprint: %v", err)), nil
+	}
+
+	summary := map[string]interface{}{
+		"sprint_id":      sprintID,
+		"completed":      true,
+		"finished_tasks": finished,
+		"carried_over":   moved,
+		"carry_over":     carryOver,
+	}
+	if nextSprintID != 0 {
+		summary["next_sprint_id"] = nextSprintID
+	}
+	resultBytes, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+// timeEntry is an amount of tracked time attributed to a user and task, and to the moment
+// it was logged when the activity history tells (At is zero otherwise).
+type timeEntry struct {
+	At        time.Time
+	UserID    int
+	ProjectID int
+	TaskID    int
+	TaskTitle string
+	Spent     float64
+	Estimated float64
+}
+
+// subtaskAssignee returns the user a subtask's time is booked to when nobody else tracked it.
+func subtaskAssignee(task, subtask map[string]interface{}) int {
+	if userID := asInt(subtask["user_id"]); userID != 0 {
+		return userID
+	}
+	return asInt(task["owner_id"])
+}
+
+// trackedTime splits the time tracked on a task into entries per user. Subtask time comes
+// from tracked, the hours getSubtaskTimeSpent reports per subtask and user (the subtask's
+// time_spent, booked to its assignee, stands in when the timers recorded nothing); a task
+// without subtasks contributes its own time_spent for its assignee. The time_spent snapshots
+// carried by task and subtask events date as much of that time as they account for, and the
+// rest is returned undated rather than guessed from modification or timer dates.
+func trackedTime(task map[string]interface{}, subtasks []map[string]interface{}, tracked map[int]map[int]float64, events []map[string]interface{}) []timeEntry {
+	taskID := asInt(task["id"])
+	base := timeEntry{
+		ProjectID: asInt(task["project_id"]),
+		TaskID:    taskID,
+		TaskTitle: asString(task["title"]),
+	}
+
+	type item struct {
+		key    string
+		spent  map[int]float64
+		userID int
+	}
+	var items []item
+	if len(subtasks) > 0 {
+		for _, subtask := range subtasks {
+			userID := subtaskAssignee(task, subtask)
+			spent := map[int]float64{}
+			for user, hours := range tracked[asInt(subtask["id"])] {
+				if hours > 0 {
+					spent[user] = hours
+				}
+			}
+			if len(spent) == 0 && asFloat(subtask["time_spent"]) > 0 {
+				spent[userID] = asFloat(subtask["time_spent"])
+			}
+			items = append(items, item{key: "subtask:" + asString(subtask["id"]), spent: spent, userID: userID})
+		}
+	} else if spent := asFloat(task["time_spent"]); spent > 0 {
+		userID := asInt(task["owner_id"])
+		items = append(items, item{key: "task", spent: map[int]float64{userID: spent}, userID: userID})
+	}
+
+	var entries []timeEntry
+	for _, it := range items {
+		remaining := map[int]float64{}
+		for user, hours := range it.spent {
+			remaining[user] = hours
+		}
+		logged := 0.0
+		for _, event := range events {
+			if asInt(event["task_id"]) != taskID {
+				continue
+			}
+			snapshot := asMap(event["subtask"])
+			if it.key == "task" {
+				if len(snapshot) > 0 {
+					continue
+				}
+				snapshot = asMap(event["task"])
+			} else if "subtask:"+asString(snapshot["id"]) != it.key {
+				continue
+			}
+			if _, ok := snapshot["time_spent"]; !ok {
+				continue
+			}
+			delta := asFloat(snapshot["time_spent"]) - logged
+			if delta <= 0 {
+				continue
+			}
+			logged += delta
+			userID := it.userID
+			if snapshotUser := asInt(snapshot["user_id"]); it.key != "task" && snapshotUser != 0 {
+				userID = snapshotUser
+			}
+			if delta > remaining[userID] {
+				delta = remaining[userID]
+			}
+			if delta <= 0.001 {
+				continue
+			}
+			entry := base
+			entry.At = time.Unix(int64(asInt(event["date_creation"])), 0)
+			entry.UserID = userID
+			entry.Spent = delta
+			entries = append(entries, entry)
+			remaining[userID] -= delta
+		}
+		var users []int
+		for user := range remaining {
+			users = append(users, user)
+		}
+		sort.Ints(users)
+		for _, user := range users {
+			if remaining[user] <= 0.001 {
+				continue
+			}
+			entry := base
+			entry.UserID = user
+			entry.Spent = remaining[user]
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// timesheetRow is the tracked and estimated time of one period/user/project (and task).
+type timesheetRow struct {
+	Period    string  `json:"period"`
+	User      string  `json:"user"`
+	Project   string  `json:"project"`
+	TaskID    int     `json:"task_id,omitempty"`
+	Task      string  `json:"task,omitempty"`
+	Spent     float64 `json:"time_spent"`
+	Estimated float64 `json:"time_estimated"`
+	Variance  float64 `json:"variance"`
+}
+
+func (kc *kanboardClient) timesheetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	loc := kc.location(ctx)
+	from, to, err := dateRangeArgs(request, loc, 30)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	period := request.GetString("period", "week")
+	if period != "day" && period != "week" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported period '%s' (expected day or week)", period)), nil
+	}
+	format := request.GetString("format", "json")
+	if format != "json" && format != "csv" && format != "markdown" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported format '%s' (expected json, csv or markdown)", format)), nil
+	}
+	byTask := request.GetBool("by_task", false)
+
+	userID := -1
+	if user := request.GetString("user", ""); user != "" {
+		userID, err = kc.resolveUserID(ctx, user)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	var projectIDs []int
+	if projectID := request.GetInt("project_id", 0); projectID != 0 {
+		projectIDs = []int{projectID}
+	} else {
+		projects, err := kc.visibleProjects(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for projectID := range projects {
+			projectIDs = append(projectIDs, projectID)
+		}
+		sort.Ints(projectIDs)
+	}
+
+	resolver := kc.newNameResolver(ctx)
+	var entries []timeEntry
+	estimates := map[int]float64{}
+	modifiedInRange := map[int]bool{}
+	for _, projectID := range projectIDs {
+		tasks, err := kc.projectTasks(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		events, err := kc.fetchProjectEvents(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		perTask := make([][]timeEntry, len(tasks))
+		errs := make([]error, len(tasks))
+		forEachConcurrent(len(tasks), 8, func(i int) {
+			task := tasks[i]
+			result, err := kc.callKanboardAPI(ctx, "getAllSubtasks", map[string]int{"task_id": asInt(task["id"])})
+			if err != nil {
+				errs[i] = fmt.Errorf("subtasks of task %d: %w", asInt(task["id"]), err)
+				return
+			}
+			var subtasks []map[string]interface{}
+			tracked := map[int]map[int]float64{}
+			estimated := 0.0
+			for _, item := range asList(result) {
+				subtask := asMap(item)
+				subtasks = append(subtasks, subtask)
+				estimated += asFloat(subtask["time_estimated"])
+				subtaskID := asInt(subtask["id"])
+				users := []int{subtaskAssignee(task, subtask)}
+				if userID > 0 && userID != users[0] {
+					users = append(users, userID)
+				}
+				tracked[subtaskID] = map[int]float64{}
+				for _, user := range users {
+					if user == 0 {
+						continue
+					}
+					spent, err := kc.callKanboardAPI(ctx, "getSubtaskTimeSpent", map[string]int{"subtask_id": subtaskID, "user_id": user})
+					if err != nil {
+						errs[i] = fmt.Errorf("time spent on subtask %d: %w", subtaskID, err)
+						return
+					}
+					tracked[subtaskID][user] = asFloat(spent)
+				}
+			}
+			// Kanboard sums subtask estimates into the task; the sum only stands in when the
+			// task carries none.
+			if asFloat(task["time_estimated"]) > 0 || len(subtasks) == 0 {
+				estimated = asFloat(task["time_estimated"])
+			}
+			entries := trackedTime(task, subtasks, tracked, events)
+			if len(entries) > 0 {
+				entries[0].Estimated = estimated
+			}
+			perTask[i] = entries
+		})
+		for i, err := range errs {
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			taskID := asInt(tasks[i]["id"])
+			modified := time.Unix(int64(asInt(tasks[i]["date_modification"])), 0)
+			modifiedInRange[taskID] = !modified.Before(from) && !modified.After(to)
+			if len(perTask[i]) > 0 {
+				estimates[taskID] = perTask[i][0].Estimated
+				perTask[i][0].Estimated = 0
+			}
+			entries = append(entries, perTask[i]...)
+		}
+	}
+
+	// Undated time is reported for tasks the range touched: those modified or with dated
+	// time in it. The estimate of each such task is counted once, with its first entry.
+	touched := map[int]bool{}
+	for _, entry := range entries {
+		if !entry.At.IsZero() && !entry.At.Before(from) && !entry.At.After(to) {
+			touched[entry.TaskID] = true
+		}
+	}
+	var kept []timeEntry
+	for _, entry := range entries {
+		if entry.At.IsZero() {
+			if !touched[entry.TaskID] && !modifiedInRange[entry.TaskID] {
+				continue
+			}
+		} else if entry.At.Before(from) || entry.At.After(to) {
+			continue
+		}
+		if userID >= 0 && entry.UserID != userID {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	estimated := map[int]bool{}
+	undated := 0.0
+	for i := range kept {
+		if !estimated[kept[i].TaskID] {
+			estimated[kept[i].TaskID] = true
+			kept[i].Estimated = estimates[kept[i].TaskID]
+		}
+		if kept[i].At.IsZero() {
+			undated += kept[i].Spent
+		}
+	}
+	var warnings []string
+	if undated > 0.001 {
+		warnings = append(warnings, fmt.Sprintf("%gh of tracked time could not be dated from the activity history (Kanboard only returns the latest events of a project, about 50) and is reported under the \"undated\" period", roundTo(undated, 2)))
+	}
+
+	rowsByKey := map[string]*timesheetRow{}
+	totalsByUser := map[string]*timesheetRow{}
+	totalsByProject := map[string]*timesheetRow{}
+	total := &timesheetRow{}
+	for _, entry := range kept {
+		if entry.Spent == 0 && entry.Estimated == 0 {
+			continue
+		}
+		row := timesheetRow{
+			Period:  "undated",
+			User:    resolver.userName(ctx, entry.UserID),
+			Project: resolver.projectName(ctx, entry.ProjectID),
+		}
+		if !entry.At.IsZero() {
+			row.Period = entry.At.In(loc).Format("2006-01-02")
+			if period == "week" {
+				row.Period = isoWeek(entry.At.In(loc))
+			}
+		}
+		if row.User == "" {
+			row.User = "unassigned"
+		}
+		if byTask {
+			row.TaskID, row.Task = entry.TaskID, entry.TaskTitle
+		}
+		key := fmt.Sprintf("%s|%s|%s|%d", row.Period, row.User, row.Project, row.TaskID)
+		if rowsByKey[key] == nil {
+			rowsByKey[key] = &row
+		}
+		if totalsByUser[row.User] == nil {
+			totalsByUser[row.User] = &timesheetRow{User: row.User}
+		}
+		if totalsByProject[row.Project] == nil {
+			totalsByProject[row.Project] = &timesheetRow{Project: row.Project}
+		}
+		for _, target := range []*timesheetRow{rowsByKey[key], totalsByUser[row.User], totalsByProject[row.Project], total} {
+			target.Spent += entry.Spent
+			target.Estimated += entry.Estimated
+		}
+	}
+
+	finish := func(rows map[string]*timesheetRow) []timesheetRow {
+		var keys []string
+		for key := range rows {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		out := []timesheetRow{}
+		for _, key := range keys {
+			row := *rows[key]
+			row.Spent = roundTo(row.Spent, 2)
+			row.Estimated = roundTo(row.Estimated, 2)
+			row.Variance = roundTo(row.Spent-row.Estimated, 2)
+			out = append(out, row)
+		}
+		return out
+	}
+	rows := finish(rowsByKey)
+	grandTotal := finish(map[string]*timesheetRow{"": total})[0]
+
+	switch format {
+	case "csv":
+		var sb strings.Builder
+		writer := csv.NewWriter(&sb)
+		header := []string{"period", "user", "project"}
+		if byTask {
+			header = append(header, "task_id", "task")
+		}
+		writer.Write(append(header, "time_spent", "time_estimated", "variance"))
+		for _, row := range rows {
+			record := []string{row.Period, row.User, row.Project}
+			if byTask {
+				record = append(record, strconv.Itoa(row.TaskID), row.Task)
+			}
+			writer.Write(append(record, strconv.FormatFloat(row.Spent, 'f', -1, 64), strconv.FormatFloat(row.Estimated, 'f', -1, 64), strconv.FormatFloat(row.Variance, 'f', -1, 64)))
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to write CSV: %v", err)), nil
+		}
+		result := mcp.NewToolResultText(sb.String())
+		if len(warnings) > 0 {
+			result.Content = append(result.Content, mcp.NewTextContent("Warnings:\n- "+strings.Join(warnings, "\n- ")))
+		}
+		return result, nil
+	case "markdown":
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "# Timesheet %s → %s\n\n", from.Format("2006-01-02"), to.Format("2006-01-02"))
+		for _, warning := range warnings {
+			fmt.Fprintf(&sb, "> ⚠️ %s\n\n", warning)
+		}
+		header := "| Period | User | Project |"
+		if byTask {
+			header += " Task |"
+		}
+		sb.WriteString(header + " Spent (h) | Estimated (h) | Variance (h) |\n")
+		sb.WriteString("|" + strings.Repeat("---|", strings.Count(header, "|")+2) + "\n")
+		for _, row := range rows {
+			line := fmt.Sprintf("| %s | %s | %s |", row.Period, markdownCell(row.User), markdownCell(row.Project))
+			if byTask {
+				line += fmt.Sprintf(" #%d %s |", row.TaskID, markdownCell(row.Task))
+			}
+			fmt.Fprintf(&sb, "%s %g | %g | %+g |\n", line, row.Spent, row.Estimated, row.Variance)
+		}
+		sb.WriteString("\n## Totals by user\n\n| User | Spent (h) | Estimated (h) | Variance (h) |\n|---|---|---|---|\n")
+		for _, row := range finish(totalsByUser) {
+			fmt.Fprintf(&sb, "| %s | %g | %g | %+g |\n", markdownCell(row.User), row.Spent, row.Estimated, row.Variance)
+		}
+		sb.WriteString("\n## Totals by project\n\n| Project | Spent (h) | Estimated (h) | Variance (h) |\n|---|---|---|---|\n")
+		for _, row := range finish(totalsByProject) {
+			fmt.Fprintf(&sb, "| %s | %g | %g | %+g |\n", markdownCell(row.Project), row.Spent, row.Estimated, row.Variance)
+		}
+		fmt.Fprintf(&sb, "\n**Total:** %gh spent, %gh estimated (%+gh)\n", grandTotal.Spent, grandTotal.Estimated, grandTotal.Variance)
+		return mcp.NewToolResultText(sb.String()), nil
+	}
+
+	report := map[string]interface{}{
+		"from":       from.Format(time.RFC3339),
+		"to":         to.Format(time.RFC3339),
+		"period":     period,
+		"rows":       rows,
+		"by_user":    finish(totalsByUser),
+		"by_project": finish(totalsByProject),
+		"total":      grandTotal,
+	}
+	if len(warnings) > 0 {
+		report["warnings"] = warnings
+	}
+	resultBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+// timerMetadataKey names the task metadata entry recording when a user started a subtask
+// timer through this server, since hasSubtaskTimer only says whether one is running.
+func timerMetadataKey(subtaskID, userID int) string {
+	return fmt.Sprintf("timer_start_%d_%d", subtaskID, userID)
+}
+
+// timerTarget resolves the user and subtask a timer tool acts on: "subtask_id", or
+// "task_id" plus an optional "subtask" title, for "user" (the current user by default).
+func (kc *kanboardClient) timerTarget(ctx context.Context, request mcp.CallToolRequest) (int, map[string]interface{}, error) {
+	userID, err := kc.resolveUserID(ctx, request.GetString("user", "me"))
+	if err != nil {
+		return 0, nil, err
+	}
+	if userID == 0 {
+		return 0, nil, fmt.Errorf("a user is required for timers")
+	}
+
+	if subtaskID := request.GetInt("subtask_id", 0); subtaskID != 0 {
+		result, err := kc.callKanboardAPI(ctx, "getSubtask", map[string]int{"subtask_id": subtaskID})
+		if err != nil {
+			return 0, nil, fmt.Errorf("Failed to get subtask: %v", err)
+		}
+		subtask := asMap(result)
+		if len(subtask) == 0 {
+			return 0, nil, fmt.Errorf("Subtask %d not found", subtaskID)
+		}
+		return userID, subtask, nil
+	}
+
+	taskID := request.GetInt("task_id", 0)
+	if taskID == 0 {
+		return 0, nil, fmt.Errorf("subtask_id or task_id is required")
+	}
+	result, err := kc.callKanboardAPI(ctx, "getAllSubtasks", map[string]int{"task_id": taskID})
+	if err != nil {
+		return 0, nil, fmt.Errorf("Failed to get subtasks: %v", err)
+	}
+	title := strings.ToLower(strings.TrimSpace(request.GetString("subtask", "")))
+
+	var candidates []map[string]interface{}
+	for _, item := range asList(result) {
+		subtask := asMap(item)
+		subtaskTitle := strings.ToLower(asString(subtask["title"]))
+		if title != "" {
+			if subtaskTitle == title {
+				return userID, subtask, nil
+			}
+			if strings.Contains(subtaskTitle, title) {
+				candidates = append(candidates, subtask)
+			}
+			continue
+		}
+		// Without a title, pick among the unfinished subtasks that are the user's or nobody's.
+		if asInt(subtask["status"]) != 2 && (asInt(subtask["user_id"]) == userID || asInt(subtask["user_id"]) == 0) {
+			candidates = append(candidates, subtask)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		if title != "" {
+			return 0, nil, fmt.Errorf("no subtask of task %d matches '%s'", taskID, title)
+		}
+		return 0, nil, fmt.Errorf("task %d has no open subtask to track time on", taskID)
+	case 1:
+		return userID, candidates[0], nil
+	}
+	var names []string
+	for _, candidate := range candidates {
+		names = append(names, fmt.Sprintf("#%s %s", asString(candidate["id"]), asString(candidate["title"])))
+	}
+	return 0, nil, fmt.Errorf("task %d has several matching subtasks, pass subtask or subtask_id: %s", taskID, strings.Join(names, ", "))
+}
+
+// withTimerTarget adds the arguments timerTarget reads.
+func withTimerTarget() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber("subtask_id",
+			mcp.Description("ID of the subtask to track (optional if task_id is given)"),
+		)(t)
+		mcp.WithNumber("task_id",
+			mcp.Description("ID of the task whose subtask to track (optional if subtask_id is given)"),
+		)(t)
+		mcp.WithString("subtask",
+			mcp.Description("Title of the subtask within task_id, needed when the task has several open subtasks (optional)"),
+		)(t)
+		mcp.WithString("user",
+			mcp.Description("User ID or username, defaults to the current user (optional)"),
+		)(t)
+	}
+}
+
+func (kc *kanboardClient) timerRunning(ctx context.Context, subtaskID, userID int) (bool, error) {
+	result, err := kc.callKanboardAPI(ctx, "hasSubtaskTimer", map[string]int{"subtask_id": subtaskID, "user_id": userID})
+	if err != nil {
+		return false, fmt.Errorf("Failed to check subtask timer: %v", err)
+	}
+	running, _ := result.(bool)
+	return running, nil
+}
+
+func (kc *kanboardClient) startTimer(ctx context.Context, subtask map[string]interface{}, userID int) error {
+	subtaskID := asInt(subtask["id"])
+	if _, err := kc.callKanboardAPI(ctx, "setSubtaskStartTime", map[string]int{"subtask_id": subtaskID, "user_id": userID}); err != nil {
+		return fmt.Errorf("Failed to start timer: %v", err)
+	}
+	if _, err := kc.callKanboardAPI(ctx, "saveTaskMetadata", map[string]interface{}{
+		"task_id": asInt(subtask["task_id"]),
+		"values":  map[string]string{timerMetadataKey(subtaskID, userID): strconv.FormatInt(time.Now().Unix(), 10)},
+	}); err != nil {
+		return fmt.Errorf("Timer started but its start time could not be recorded: %v", err)
+	}
+	return nil
+}
+
+func (kc *kanboardClient) stopTimer(ctx context.Context, subtaskID, taskID, userID int) error {
+	if _, err := kc.callKanboardAPI(ctx, "setSubtaskEndTime", map[string]int{"subtask_id": subtaskID, "user_id": userID}); err != nil {
+		return fmt.Errorf("Failed to stop timer: %v", err)
+	}
+	if _, err := kc.callKanboardAPI(ctx, "removeTaskMetadata", []interface{}{taskID, timerMetadataKey(subtaskID, userID)}); err != nil {
+		return fmt.Errorf("Timer stopped but its recorded start time could not be removed: %v", err)
+	}
+	return nil
+}
+
+// runningTimer is a subtask timer that is currently running.
+type runningTimer struct {
+	SubtaskID int     `json:"subtask_id"`
+	Subtask   string  `json:"subtask"`
+	TaskID    int     `json:"task_id"`
+	Task      string  `json:"task"`
+	UserID    int     `json:"user_id"`
+	Started   string  `json:"started,omitempty"`
+	Hours     float64 `json:"running_hours,omitempty"`
+	Forgotten bool    `json:"forgotten"`
+	Stopped   bool    `json:"stopped,omitempty"`
+}
+
+// runningTimers checks every subtask assigned to the user or to nobody in the active tasks
+// of the visible projects. It fails rather than miss a timer when a lookup fails.
+func (kc *kanboardClient) runningTimers(ctx context.Context, userID int) ([]runningTimer, error) {
+	projects, err := kc.visibleProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []map[string]interface{}
+	for projectID := range projects {
+		result, err := kc.callKanboardAPI(ctx, "getAllTasks", map[string]int{"project_id": projectID, "status_id": 1})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get tasks: %v", err)
+		}
+		for _, item := range asList(result) {
+			tasks = append(tasks, asMap(item))
+		}
+	}
+
+	loc := kc.location(ctx)
+	perTask := make([][]runningTimer, len(tasks))
+	perTaskFailures := make([][]string, len(tasks))
+	forEachConcurrent(len(tasks), 8, func(i int) {
+		taskID := asInt(tasks[i]["id"])
+		result, err := kc.callKanboardAPI(ctx, "getAllSubtasks", map[string]int{"task_id": taskID})
+		if err != nil {
+			perTaskFailures[i] = append(perTaskFailures[i], fmt.Sprintf("subtasks of task %d: %v", taskID, err))
+			return
+		}
+		for _, item := range asList(result) {
+			subtask := asMap(item)
+			if assignee := asInt(subtask["user_id"]); assignee != userID && assignee != 0 {
+				continue
+			}
+			running, err := kc.timerRunning(ctx, asInt(subtask["id"]), userID)
+			if err != nil {
+				perTaskFailures[i] = append(perTaskFailures[i], fmt.Sprintf("subtask %s: %v", asString(subtask["id"]), err))
+				continue
+			}
+			if !running {
+				continue
+			}
+			timer := runningTimer{
+				SubtaskID: asInt(subtask["id"]),
+				Subtask:   asString(subtask["title"]),
+				TaskID:    taskID,
+				Task:      asString(tasks[i]["title"]),
+				UserID:    userID,
+			}
+			started := asInt(subtask["timer_start_date"])
+			if started == 0 {
+				value, err := kc.callKanboardAPI(ctx, "getTaskMetadataByName", []interface{}{taskID, timerMetadataKey(timer.SubtaskID, userID)})
+				if err != nil {
+					perTaskFailures[i] = append(perTaskFailures[i], fmt.Sprintf("timer start of subtask %d: %v", timer.SubtaskID, err))
+					continue
+				}
+				started = asInt(value)
+			}
+			if started > 0 {
+				timer.Started = formatTimestamp(started, loc)
+				timer.Hours = roundTo(time.Since(time.Unix(int64(started), 0)).Hours(), 2)
+			}
+			perTask[i] = append(perTask[i], timer)
+		}
+	})
+
+	timers := []runningTimer{}
+	var failures []string
+	for i, taskTimers := range perTask {
+		timers = append(timers, taskTimers...)
+		failures = append(failures, perTaskFailures[i]...)
+	}
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("Failed to check timers: %s", strings.Join(failures, "; "))
+	}
+	sort.Slice(timers, func(i, j int) bool { return timers[i].SubtaskID < timers[j].SubtaskID })
+	return timers, nil
+}
+
+func (kc *kanboardClient) startTimerHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	userID, subtask, err := kc.timerTarget(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	running, err := kc.timerRunning(ctx, asInt(subtask["id"]), userID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if running {
+		return mcp.NewToolResultError(fmt.Sprintf("A timer is already running on subtask #%s %s", asString(subtask["id"]), asString(subtask["title"]))), nil
+	}
+	if err := kc.startTimer(ctx, subtask, userID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Timer started on subtask #%s %s (task #%s)", asString(subtask["id"]), asString(subtask["title"]), asString(subtask["task_id"]))), nil
+}
+
+func (kc *kanboardClient) stopTimerHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Without a target, stop whatever the user has running.
+	if request.GetInt("subtask_id", 0) == 0 && request.GetInt("task_id", 0) == 0 {
+		userID, err := kc.resolveUserID(ctx, request.GetString("user", "me"))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if userID == 0 {
+			return mcp.NewToolResultError("a user is required for timers"), nil
+		}
+		timers, err := kc.runningTimers(ctx, userID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if len(timers) == 0 {
+			return mcp.NewToolResultText("No timer is running"), nil
+		}
+		var stopped []string
+		for _, timer := range timers {
+			if err := kc.stopTimer(ctx, timer.SubtaskID, timer.TaskID, userID); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			stopped = append(stopped, fmt.Sprintf("#%d %s", timer.SubtaskID, timer.Subtask))
+		}
+		return mcp.NewToolResultText("Timer stopped on subtask " + strings.Join(stopped, ", ")), nil
+	}
+
+	userID, subtask, err := kc.timerTarget(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	running, err := kc.timerRunning(ctx, asInt(subtask["id"]), userID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !running {
+		return mcp.NewToolResultError(fmt.Sprintf("No timer is running on subtask #%s %s", asString(subtask["id"]), asString(subtask["title"]))), nil
+	}
+	if err := kc.stopTimer(ctx, asInt(subtask["id"]), asInt(subtask["task_id"]), userID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Timer stopped on subtask #%s %s", asString(subtask["id"]), asString(subtask["title"]))), nil
+}
+
+func (kc *kanboardClient) switchTimerHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	userID, subtask, err := kc.timerTarget(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	timers, err := kc.runningTimers(ctx, userID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var stopped []string
+	alreadyRunning := false
+	for _, timer := range timers {
+		if timer.SubtaskID == asInt(subtask["id"]) {
+			alreadyRunning = true
+			continue
+		}
+		if err := kc.stopTimer(ctx, timer.SubtaskID, timer.TaskID, userID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		stopped = append(stopped, fmt.Sprintf("#%d %s", timer.SubtaskID, timer.Subtask))
+	}
+	if !alreadyRunning {
+		if err := kc.startTimer(ctx, subtask, userID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	message := fmt.Sprintf("Timer running on subtask #%s %s", asString(subtask["id"]), asString(subtask["title"]))
+	if len(stopped) > 0 {
+		message += "; stopped " + strings.Join(stopped, ", ")
+	}
+	return mcp.NewToolResultText(message), nil
+}
+
+func (kc *kanboardClient) runningTimersHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	userID, err := kc.resolveUserID(ctx, request.GetString("user", "me"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	maxHours := request.GetFloat("max_hours", 10)
+	stop := request.GetString("stop", "none")
+	if stop != "none" && stop != "forgotten" && stop != "all" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported stop '%s' (expected none, forgotten or all)", stop)), nil
+	}
+
+	timers, err := kc.runningTimers(ctx, userID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	midnight := time.Now().In(kc.location(ctx))
+	midnight = time.Date(midnight.Year(), midnight.Month(), midnight.Day(), 0, 0, 0, 0, midnight.Location())
+	for i := range timers {
+		timer := &timers[i]
+		// A timer is forgotten when it has run past max_hours or was started before today in the Kanboard timezone.
+		if timer.Started != "" {
+			started, _ := time.Parse(time.RFC3339, timer.Started)
+			timer.Forgotten = timer.Hours > maxHours || started.Before(midnight)
+		}
+		if stop == "all" || (stop == "forgotten" && timer.Forgotten) {
+			if err := kc.stopTimer(ctx, timer.SubtaskID, timer.TaskID, userID); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			timer.Stopped = true
+		}
+	}
+
+	resultBytes, err := json.MarshalIndent(map[string]interface{}{
+		"user_id": userID,
+		"timers":  timers,
+	}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+// checkWIPLimit refuses to move a task into a column that is already at its task_limit.
+// Moves within the same column never change its count and are always allowed.
+func (kc *kanboardClient) checkWIPLimit(ctx context.Context, projectID, taskID, columnID int) error {
+	result, err := kc.callKanboardAPI(ctx, "getColumns", map[string]int{"project_id": projectID})
+	if err != nil {
+		return fmt.Errorf("Failed to get columns: %v", err)
+	}
+	limit := 0
+	title := ""
+	for _, item := range asList(result) {
+		column := asMap(item)
+		if asInt(column["id"]) == columnID {
+			limit = asInt(column["task_limit"])
+			title = asString(column["title"])
+		}
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	swimlanes, err := kc.fetchBoard(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	for _, swimlane := range swimlanes {
+		for _, column := range swimlane.Columns {
+			for _, task := range column.Tasks {
+				if asInt(task["id"]) == taskID && column.ID == columnID {
+					return nil
+				}
+			}
+		}
+	}
+	if count := columnTaskCounts(swimlanes)[columnID]; count >= limit {
+		return fmt.Errorf("Column '%s' is at its WIP limit (%d/%d tasks); finish or move a task out of it first, or set override_wip_limit to move anyway", title, count, limit)
+	}
+	return nil
+}
+
+// resolveColumnID accepts a column ID or title (case-insensitive) within a project.
+func (kc *kanboardClient) resolveColumnID(ctx context.Context, projectID int, column string) (int, error) {
+	column = strings.TrimSpace(column)
+	result, err := kc.callKanboardAPICached(ctx, "getColumns", map[string]int{"project_id": projectID})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get columns: %v", err)
+	}
+	var titles []string
+	for _, item := range asList(result) {
+		candidate := asMap(item)
+		if asString(candidate["id"]) == column || strings.EqualFold(asString(candidate["title"]), column) {
+			return asInt(candidate["id"]), nil
+		}
+		titles = append(titles, asString(candidate["title"]))
+	}
+	return 0, fmt.Errorf("column '%s' not found in project %d (columns: %s)", column, projectID, strings.Join(titles, ", "))
+}
+
+// resolveSwimlaneID accepts a swimlane ID or name (case-insensitive) within a project.
+func (kc *kanboardClient) resolveSwimlaneID(ctx context.Context, projectID int, swimlane string) (int, error) {
+	swimlane = strings.TrimSpace(swimlane)
+	result, err := kc.callKanboardAPICached(ctx, "getAllSwimlanes", map[string]int{"project_id": projectID})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get swimlanes: %v", err)
+	}
+	var names []string
+	for _, item := range asList(result) {
+		candidate := asMap(item)
+		if asString(candidate["id"]) == swimlane || strings.EqualFold(asString(candidate["name"]), swimlane) {
+			return asInt(candidate["id"]), nil
+		}
+		names = append(names, asString(candidate["name"]))
+	}
+	return 0, fmt.Errorf("swimlane '%s' not found in project %d (swimlanes: %s)", swimlane, projectID, strings.Join(names, ", "))
+}
+
+// boardPosition turns "top", "bottom" or "after:<task id>" into the 1-based position
+// moveTaskPosition expects within a column of a swimlane, ignoring the moved task itself.
+func boardPosition(swimlanes []boardSwimlane, swimlaneID, columnID, taskID int, position string) (int, error) {
+	var others []int
+	for _, swimlane := range swimlanes {
+		if swimlane.ID != swimlaneID {
+			continue
+		}
+		for _, column := range swimlane.Columns {
+			if column.ID != columnID {
+				continue
+			}
+			for _, task := range column.Tasks {
+				if id := asInt(task["id"]); id != taskID {
+					others = append(others, id)
+				}
+			}
+		}
+	}
+
+	position = strings.ToLower(strings.TrimSpace(position))
+	switch {
+	case position == "top":
+		return 1, nil
+	case position == "" || position == "bottom":
+		return len(others) + 1, nil
+	case strings.HasPrefix(position, "after:"):
+		after, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(position, "after:"), "#"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid position '%s' (expected after:<task id>)", position)
+		}
+		for i, id := range others {
+			if id == after {
+				return i + 2, nil
+			}
+		}
+		return 0, fmt.Errorf("task %d is not in the target column and swimlane", after)
+	}
+	return 0, fmt.Errorf("unsupported position '%s' (expected top, bottom or after:<task id>)", position)
+}
+
+func (kc *kanboardClient) moveTaskHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	taskID, err := request.RequireInt("task_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	columnArg, err := request.RequireString("column")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	taskResult, err := kc.callKanboardAPI(ctx, "getTask", map[string]int{"task_id": taskID})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get task: %v", err)), nil
+	}
+	task := asMap(taskResult)
+	if len(task) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Task %d not found", taskID)), nil
+	}
+	projectID := asInt(task["project_id"])
+
+	columnID, err := kc.resolveColumnID(ctx, projectID, columnArg)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	swimlaneID := asInt(task["swimlane_id"])
+	if swimlane := request.GetString("swimlane", ""); swimlane != "" {
+		swimlaneID, err = kc.resolveSwimlaneID(ctx, projectID, swimlane)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	if !request.GetBool("override_wip_limit", false) {
+		if err := kc.checkWIPLimit(ctx, projectID, taskID, columnID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	swimlanes, err := kc.fetchBoard(ctx, projectID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	position, err := boardPosition(swimlanes, swimlaneID, columnID, taskID, request.GetString("position", "bottom"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	params := map[string]interface{}{
+		"project_id":  projectID,
+		"task_id":     taskID,
+		"column_id":   columnID,
+		"position":    position,
+		"swimlane_id": swimlaneID,
+	}
+	result, err := kc.callKanboardAPI(ctx, "moveTaskPosition", params)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to move task: %v", err)), nil
+	}
+	if moved, ok := result.(bool); ok && !moved {
+		return mcp.NewToolResultError(fmt.Sprintf("Kanboard refused to move task %d", taskID)), nil
+	}
+
+	resolver := kc.newNameResolver(ctx)
+	return mcp.NewToolResultText(fmt.Sprintf("Moved task #%d to %s / %s at position %d",
+		taskID, resolver.columnName(ctx, projectID, columnID), resolver.swimlaneName(ctx, projectID, swimlaneID), position)), nil
+}
+
+// partialUpdate builds the params of an update call from the arguments of a request.
+// An argument that is absent leaves the field untouched, one that is present is sent as
+// given (including 0, "" and false), and one that is null or listed in the "clear"
+// argument is reset to its empty value.
+type partialUpdate struct {
+	request mcp.CallToolRequest
+	params  map[string]interface{}
+	cleared map[string]bool
+	known   map[string]bool
+}
+
+func newPartialUpdate(request mcp.CallToolRequest, params map[string]interface{}) *partialUpdate {
+	u := &partialUpdate{request: request, params: params, cleared: map[string]bool{}, known: map[string]bool{}}
+	for _, field := range request.GetStringSlice("clear", nil) {
+		u.cleared[field] = true
+	}
+	return u
+}
+
+// state reports whether an argument was provided and whether it asks for the field to be cleared.
+func (u *partialUpdate) state(arg string) (provided, clear bool) {
+	u.known[arg] = true
+	if u.cleared[arg] {
+		return true, true
+	}
+	value, ok := u.request.GetArguments()[arg]
+	if !ok {
+		return false, false
+	}
+	return true, value == nil
+}
+
+func (u *partialUpdate) intField(arg, apiKey string) {
+	if provided, clear := u.state(arg); clear {
+		u.params[apiKey] = 0
+	} else if provided {
+		u.params[apiKey] = u.request.GetInt(arg, 0)
+	}
+}
+
+func (u *partialUpdate) floatField(arg, apiKey string) {
+	if provided, clear := u.state(arg); clear {
+		u.params[apiKey] = 0
+	} else if provided {
+		u.params[apiKey] = u.request.GetFloat(arg, 0)
+	}
+}
+
+func (u *partialUpdate) stringField(arg, apiKey string) {
+	if provided, clear := u.state(arg); clear {
+		u.params[apiKey] = ""
+	} else if provided {
+		u.params[apiKey] = u.request.GetString(arg, "")
+	}
+}
+
+func (u *partialUpdate) boolField(arg, apiKey string) {
+	if provided, clear := u.state(arg); clear {
+		u.params[apiKey] = false
+	} else if provided {
+		u.params[apiKey] = u.request.GetBool(arg, false)
+	}
+}
+
+func (u *partialUpdate) stringsField(arg, apiKey string) {
+	if provided, clear := u.state(arg); clear {
+		u.params[apiKey] = []string{}
+	} else if provided {
+		u.params[apiKey] = u.request.GetStringSlice(arg, []string{})
+	}
+}
+
+// dateField converts a provided date with parse; an empty or cleared date unsets the field.
+func (u *partialUpdate) dateField(arg, apiKey string, parse func(string) (string, error)) error {
+	provided, clear := u.state(arg)
+	value := strings.TrimSpace(u.request.GetString(arg, ""))
+	if clear || (provided && value == "") {
+		u.params[apiKey] = ""
+		return nil
+	}
+	if !provided {
+		return nil
+	}
+	date, err := parse(value)
+	if err != nil {
+		return fmt.Errorf("%s: %v", arg, err)
+	}
+	u.params[apiKey] = date
+	return nil
+}
+
+// unknownCleared rejects "clear" entries that don't name a field of the update.
+func (u *partialUpdate) unknownCleared() error {
+	var unknown []string
+	for field := range u.cleared {
+		if !u.known[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("cannot clear unknown field(s): %s", strings.Join(unknown, ", "))
+}
+
+// withClear adds the "clear" argument read by partialUpdate.
+func withClear(fields ...string) mcp.ToolOption {
+	return mcp.WithArray("clear",
+		mcp.WithStringEnumItems(fields),
+		mcp.Description("Fields to reset to their empty value, e.g. [\""+fields[0]+"\"]; passing a field as null does the same, while omitting it leaves it unchanged (optional)"),
+	)
+}
+
+// dependencyLabels maps internal link labels to whether the linked ("opposite") task comes
+// before the task (true) or after it (false) in the dependency order.
+var dependencyLabels = map[string]bool{
+	"blocks":             false,
+	"is blocked by":      true,
+	"depends on":         true,
+	"is a dependency of": false,
+	"is required by":     false,
+	"requires":           true,
+}
+
+// dependencyNode is a task of a dependency graph.
+type dependencyNode struct {
+	ID       int     `json:"id"`
+	Title    string  `json:"title"`
+	Open     bool    `json:"open"`
+	Weight   float64 `json:"weight"`
+	External bool    `json:"external,omitempty"`
+}
+
+// dependencyGraph holds "must finish before" edges between tasks.
+type dependencyGraph struct {
+	Nodes map[int]*dependencyNode
+	Next  map[int][]int
+	Prev  map[int][]int
+}
+
+func (g *dependencyGraph) addEdge(from, to int) {
+	for _, existing := range g.Next[from] {
+		if existing == to {
+			return
+		}
+	}
+	g.Next[from] = append(g.Next[from], to)
+	g.Prev[to] = append(g.Prev[to], from)
+}
+
+func (g *dependencyGraph) sortedIDs() []int {
+	ids := make([]int, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// buildDependencyGraph crawls the internal links of every task of a project.
+func (kc *kanboardClient) buildDependencyGraph(ctx context.Context, projectID int, weight string) (*dependencyGraph, error) {
+	tasks, err := kc.projectTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	graph := &dependencyGraph{Nodes: map[int]*dependencyNode{}, Next: map[int][]int{}, Prev: map[int][]int{}}
+	for _, task := range tasks {
+		node := &dependencyNode{ID: asInt(task["id"]), Title: asString(task["title"]), Open: asInt(task["is_active"]) == 1}
+		if weight == "estimate" {
+			node.Weight = asFloat(task["time_estimated"])
+		} else {
+			node.Weight = asFloat(task["score"])
+		}
+		graph.Nodes[node.ID] = node
+	}
+
+	links := make([][]map[string]interface{}, len(tasks))
+	errs := make([]error, len(tasks))
+	forEachConcurrent(len(tasks), 8, func(i int) {
+		result, err := kc.callKanboardAPI(ctx, "getAllTaskLinks", map[string]int{"task_id": asInt(tasks[i]["id"])})
+		if err != nil {
+			errs[i] = fmt.Errorf("links of task %d: %v", asInt(tasks[i]["id"]), err)
+			return
+		}
+		for _, item := range asList(result) {
+			links[i] = append(links[i], asMap(item))
+		}
+	})
+	// A missing edge would hide cycles and shorten the critical path, so the graph is
+	// only built from the links of every task.
+	if err := joinErrors(errs); err != nil {
+		return nil, err
+	}
+
+	for i, task := range tasks {
+		taskID := asInt(task["id"])
+		for _, link := range links[i] {
+			before, ok := dependencyLabels[strings.ToLower(asString(link["label"]))]
+			if !ok {
+				continue
+			}
+			otherID := asInt(link["opposite_task_id"])
+			if otherID == 0 {
+				otherID = asInt(link["task_id"])
+			}
+			if otherID == 0 || otherID == taskID {
+				continue
+			}
+			if _, known := graph.Nodes[otherID]; !known {
+				graph.Nodes[otherID] = &dependencyNode{ID: otherID, Title: asString(link["title"]), Open: asInt(link["is_active"]) == 1, External: true}
+			}
+			if before {
+				graph.addEdge(otherID, taskID)
+			} else {
+				graph.addEdge(taskID, otherID)
+			}
+		}
+	}
+	for id := range graph.Next {
+		sort.Ints(graph.Next[id])
+	}
+	return graph, nil
+}
+
+// dependencyCycles returns the strongly connected components of the graph that form
+// cycles (Tarjan's algorithm).
+func dependencyCycles(graph *dependencyGraph) [][]int {
+	index := 0
+	indices := map[int]int{}
+	lowlink := map[int]int{}
+	onStack := map[int]bool{}
+	var stack []int
+	var cycles [][]int
+
+	var visit func(id int)
+	visit = func(id int) {
+		indices[id], lowlink[id] = index, index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+		for _, next := range graph.Next[id] {
+			if _, seen := indices[next]; !seen {
+				visit(next)
+				lowlink[id] = min(lowlink[id], lowlink[next])
+			} else if onStack[next] {
+				lowlink[id] = min(lowlink[id], indices[next])
+			}
+		}
+		if lowlink[id] != indices[id] {
+			return
+		}
+		var component []int
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Ints(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, id := range graph.sortedIDs() {
+		if _, seen := indices[id]; !seen {
+			visit(id)
+		}
+	}
+	return cycles
+}
+
+// criticalPath returns the heaviest chain of open tasks, skipping tasks caught in cycles.
+func criticalPath(graph *dependencyGraph, inCycle map[int]bool) ([]int, float64) {
+	usable := func(id int) bool {
+		node := graph.Nodes[id]
+		return node != nil && node.Open && !inCycle[id]
+	}
+	indegree := map[int]int{}
+	for _, id := range graph.sortedIDs() {
+		if !usable(id) {
+			continue
+		}
+		for _, next := range graph.Next[id] {
+			if usable(next) {
+				indegree[next]++
+			}
+		}
+	}
+	var queue []int
+	for _, id := range graph.sortedIDs() {
+		if usable(id) && indegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+
+	best := map[int]float64{}
+	length := map[int]int{}
+	previous := map[int]int{}
+	endID, endWeight, endLength := 0, -1.0, 0
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		best[id] += graph.Nodes[id].Weight
+		length[id]++
+		// Ties go to the longer chain, so unweighted tasks still form a path.
+		if best[id] > endWeight || (best[id] == endWeight && length[id] > endLength) {
+			endID, endWeight, endLength = id, best[id], length[id]
+		}
+		for _, next := range graph.Next[id] {
+			if !usable(next) {
+				continue
+			}
+			// Until it is dequeued, best[next] is the heaviest chain leading to it.
+			if previous[next] == 0 || best[id] > best[next] || (best[id] == best[next] && length[id] > length[next]) {
+				best[next], length[next] = best[id], length[id]
+				previous[next] = id
+			}
+			indegree[next]--
+			if indegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+	if endID == 0 {
+		return []int{}, 0
+	}
+	path := []int{endID}
+	for previous[path[0]] != 0 {
+		path = append([]int{previous[path[0]]}, path...)
+	}
+	return path, endWeight
+}
+
+func (kc *kanboardClient) dependencyGraphHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID, err := request.RequireInt("project_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	weight := request.GetString("weight", "score")
+	if weight != "score" && weight != "estimate" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported weight '%s' (expected score or estimate)", weight)), nil
+	}
+	format := request.GetString("format", "json")
+	if format != "json" && format != "mermaid" && format != "dot" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported format '%s' (expected json, mermaid or dot)", format)), nil
+	}
+	includeClosed := request.GetBool("include_closed", false)
+
+	graph, err := kc.buildDependencyGraph(ctx, projectID, weight)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cycles := dependencyCycles(graph)
+	inCycle := map[int]bool{}
+	for _, cycle := range cycles {
+		for _, id := range cycle {
+			inCycle[id] = true
+		}
+	}
+	path, pathWeight := criticalPath(graph, inCycle)
+	onPath := map[int]bool{}
+	for _, id := range path {
+		onPath[id] = true
+	}
+
+	type blockedTask struct {
+		ID        int    `json:"id"`
+		Title     string `json:"title"`
+		BlockedBy []int  `json:"blocked_by"`
+	}
+	blocked := []blockedTask{}
+	isBlocked := map[int]bool{}
+	for _, id := range graph.sortedIDs() {
+		node := graph.Nodes[id]
+		if !node.Open || node.External {
+			continue
+		}
+		var blockers []int
+		for _, prev := range graph.Prev[id] {
+			if graph.Nodes[prev].Open {
+				blockers = append(blockers, prev)
+			}
+		}
+		if len(blockers) > 0 {
+			sort.Ints(blockers)
+			blocked = append(blocked, blockedTask{ID: id, Title: node.Title, BlockedBy: blockers})
+			isBlocked[id] = true
+		}
+	}
+
+	// Only tasks that take part in a dependency are drawn; closed ones on request.
+	shown := func(id int) bool {
+		return (includeClosed || graph.Nodes[id].Open) && (len(graph.Next[id]) > 0 || len(graph.Prev[id]) > 0)
+	}
+
+	switch format {
+	case "mermaid":
+		var sb strings.Builder
+		sb.WriteString("graph LR\n")
+		for _, id := range graph.sortedIDs() {
+			if !shown(id) {
+				continue
+			}
+			label := strings.ReplaceAll(fmt.Sprintf("#%d %s", id, graph.Nodes[id].Title), "\"", "'")
+			fmt.Fprintf(&sb, "    T%d[\"%s\"]\n", id, label)
+		}
+		for _, id := range graph.sortedIDs() {
+			for _, next := range graph.Next[id] {
+				if shown(id) && shown(next) {
+					fmt.Fprintf(&sb, "    T%d --> T%d\n", id, next)
+				}
+			}
+		}
+		sb.WriteString("    classDef critical stroke:#d33,stroke-width:3px\n")
+		sb.WriteString("    classDef blocked fill:#fde2e2\n")
+		sb.WriteString("    classDef cycle fill:#fbd38d\n")
+		sb.WriteString("    classDef closed fill:#e2e8f0,color:#718096\n")
+		for _, id := range graph.sortedIDs() {
+			if !shown(id) {
+				continue
+			}
+			switch {
+			case inCycle[id]:
+				fmt.Fprintf(&sb, "    class T%d cycle\n", id)
+			case onPath[id]:
+				fmt.Fprintf(&sb, "    class T%d critical\n", id)
+			case isBlocked[id]:
+				fmt.Fprintf(&sb, "    class T%d blocked\n", id)
+			case !graph.Nodes[id].Open:
+				fmt.Fprintf(&sb, "    class T%d closed\n", id)
+			}
+		}
+		return mcp.NewToolResultText(sb.String()), nil
+	case "dot":
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "digraph project_%d {\n    rankdir=LR;\n    node [shape=box];\n", projectID)
+		for _, id := range graph.sortedIDs() {
+			if !shown(id) {
+				continue
+			}
+			label := strings.ReplaceAll(fmt.Sprintf("#%d %s", id, graph.Nodes[id].Title), "\"", "\\\"")
+			attrs := fmt.Sprintf("label=\"%s\"", label)
+			switch {
+			case inCycle[id]:
+				attrs += ", style=filled, fillcolor=orange"
+			case onPath[id]:
+				attrs += ", color=red, penwidth=3"
+			case isBlocked[id]:
+				attrs += ", style=filled, fillcolor=mistyrose"
+			case !graph.Nodes[id].Open:
+				attrs += ", style=dashed, fontcolor=gray"
+			}
+			fmt.Fprintf(&sb, "    t%d [%s];\n", id, attrs)
+		}
+		for _, id := range graph.sortedIDs() {
+			for _, next := range graph.Next[id] {
+				if !shown(id) || !shown(next) {
+					continue
+				}
+				if onPath[id] && onPath[next] {
+					fmt.Fprintf(&sb, "    t%d -> t%d [color=red, penwidth=2];\n", id, next)
+				} else {
+					fmt.Fprintf(&sb, "    t%d -> t%d;\n", id, next)
+				}
+			}
+		}
+		sb.WriteString("}\n")
+		return mcp.NewToolResultText(sb.String()), nil
+	}
+
+	var nodes []dependencyNode
+	type edge struct {
+		From int `json:"from"`
+		To   int `json:"to"`
+	}
+	edges := []edge{}
+	for _, id := range graph.sortedIDs() {
+		if shown(id) {
+			nodes = append(nodes, *graph.Nodes[id])
+		}
+		for _, next := range graph.Next[id] {
+			if shown(id) && shown(next) {
+				edges = append(edges, edge{From: id, To: next})
+			}
+		}
+	}
+	if cycles == nil {
+		cycles = [][]int{}
+	}
+	resultBytes, err := json.MarshalIndent(map[string]interface{}{
+		"project_id":    projectID,
+		"nodes":         nodes,
+		"edges":         edges,
+		"cycles":        cycles,
+		"blocked_tasks": blocked,
+		"critical_path": map[string]interface{}{
+			"tasks":  path,
+			"weight": pathWeight,
+			"unit":   weight,
+		},
+	}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+// linkType is a Kanboard link label with its opposite.
+type linkType struct {
+	ID            int
+	Label         string
+	OppositeID    int
+	OppositeLabel string
+}
+
+// resolveLinkType finds a link label through getLinkByLabel, falling back to a
+// case-insensitive match over getAllLinks.
+func (kc *kanboardClient) resolveLinkType(ctx context.Context, label string) (linkType, error) {
+	label = strings.TrimSpace(label)
+	result, err := kc.callKanboardAPICached(ctx, "getAllLinks", nil)
+	if err != nil {
+		return linkType{}, fmt.Errorf("Failed to get links: %v", err)
+	}
+	labels := map[int]string{}
+	var names []string
+	for _, item := range asList(result) {
+		link := asMap(item)
+		labels[asInt(link["id"])] = asString(link["label"])
+		names = append(names, asString(link["label"]))
+	}
+
+	var found map[string]interface{}
+	if byLabel, err := kc.callKanboardAPI(ctx, "getLinkByLabel", map[string]string{"label": label}); err == nil {
+		found = asMap(byLabel)
+	}
+	if len(found) == 0 {
+		for _, item := range asList(result) {
+			if link := asMap(item); strings.EqualFold(asString(link["label"]), label) {
+				found = link
+				break
+			}
+		}
+	}
+	if len(found) == 0 {
+		return linkType{}, fmt.Errorf("unknown relation '%s' (available: %s)", label, strings.Join(names, ", "))
+	}
+
+	link := linkType{ID: asInt(found["id"]), Label: asString(found["label"]), OppositeID: asInt(found["opposite_id"])}
+	if link.OppositeID == 0 {
+		link.OppositeID = link.ID
+	}
+	link.OppositeLabel = labels[link.OppositeID]
+	return link, nil
+}
+
+// taskLinksTo returns the internal links of a task that point at another task. getAllTaskLinks
+// reports the other task as task_id and the relation only by its label.
+func (kc *kanboardClient) taskLinksTo(ctx context.Context, taskID, otherID int) ([]map[string]interface{}, error) {
+	result, err := kc.callKanboardAPI(ctx, "getAllTaskLinks", map[string]int{"task_id": taskID})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get task links: %v", err)
+	}
+	var links []map[string]interface{}
+	for _, item := range asList(result) {
+		if link := asMap(item); asInt(link["task_id"]) == otherID {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+func (kc *kanboardClient) linkTasksHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	taskID, err := request.RequireInt("task_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	relation, err := request.RequireString("relation")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	otherID, err := request.RequireInt("other_task_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if taskID == otherID {
+		return mcp.NewToolResultError("A task cannot be linked to itself"), nil
+	}
+
+	link, err := kc.resolveLinkType(ctx, relation)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	summary := map[string]interface{}{
+		"task_id":           taskID,
+		"relation":          link.Label,
+		"other_task_id":     otherID,
+		"opposite_relation": link.OppositeLabel,
+	}
+
+	// Kanboard stores both directions, so an existing link shows up on the task's side.
+	existing, err := kc.taskLinksTo(ctx, taskID, otherID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var others []string
+	for _, candidate := range existing {
+		if strings.EqualFold(asString(candidate["label"]), link.Label) {
+			summary["created"] = false
+			summary["task_link_id"] = asInt(candidate["id"])
+			resultBytes, err := json.MarshalIndent(summary, "", "  ")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+			}
+			return mcp.NewToolResultText(string(resultBytes)), nil
+		}
+		others = append(others, asString(candidate["label"]))
+	}
+
+	result, err := kc.callKanboardAPI(ctx, "createTaskLink", map[string]int{
+		"task_id":          taskID,
+		"opposite_task_id": otherID,
+		"link_id":          link.ID,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create task link: %v", err)), nil
+	}
+	if created, ok := result.(bool); ok && !created {
+		return mcp.NewToolResultError(fmt.Sprintf("Kanboard refused to link task %d to task %d", taskID, otherID)), nil
+	}
+	summary["created"] = true
+	summary["task_link_id"] = asInt(result)
+	if len(others) > 0 {
+		summary["other_relations"] = others
+	}
+
+	resultBytes, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+func (kc *kanboardClient) unlinkTasksHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	taskID, err := request.RequireInt("task_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	otherID, err := request.RequireInt("other_task_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	label := ""
+	if relation := request.GetString("relation", ""); relation != "" {
+		link, err := kc.resolveLinkType(ctx, relation)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		label = link.Label
+	}
+
+	existing, err := kc.taskLinksTo(ctx, taskID, otherID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var removed []string
+	for _, candidate := range existing {
+		if label != "" && !strings.EqualFold(asString(candidate["label"]), label) {
+			continue
+		}
+		if _, err := kc.callKanboardAPI(ctx, "removeTaskLink", map[string]int{"task_link_id": asInt(candidate["id"])}); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to remove task link: %v", err)), nil
+		}
+		removed = append(removed, asString(candidate["label"]))
+	}
+	if len(removed) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Task %d is not linked to task %d", taskID, otherID)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Removed link(s) from task #%d to task #%d: %s", taskID, otherID, strings.Join(removed, ", "))), nil
+}
+
+// epicNode is a task of a parent/child hierarchy, rolled up over its descendants.
+type epicNode struct {
+	ID            int         `json:"id"`
+	Title         string      `json:"title"`
+	Open          bool        `json:"open"`
+	External      bool        `json:"external,omitempty"`
+	Score         float64     `json:"score"`
+	TimeEstimated float64     `json:"time_estimated"`
+	DueDate       string      `json:"due_date,omitempty"`
+	OpenSubtasks  int         `json:"open_subtasks"`
+	Rollup        *epicRollup `json:"rollup,omitempty"`
+	Children      []*epicNode `json:"children,omitempty"`
+	dateDue       int
+}
+
+// epicRollup sums up the descendants of a parent task.
+type epicRollup struct {
+	Tasks         int     `json:"tasks"`
+	Closed        int     `json:"closed"`
+	Completion    float64 `json:"completion"`
+	OpenSubtasks  int     `json:"open_subtasks"`
+	Score         float64 `json:"score"`
+	TimeEstimated float64 `json:"time_estimated"`
+	LatestDueDate string  `json:"latest_due_date,omitempty"`
+}
+
+// epicHierarchy holds the "is a parent of" edges between tasks.
+type epicHierarchy struct {
+	Nodes    map[int]*epicNode
+	Children map[int][]int
+	Parents  map[int][]int
+}
+
+func (h *epicHierarchy) addChild(parent, child int) {
+	for _, existing := range h.Children[parent] {
+		if existing == child {
+			return
+		}
+	}
+	h.Children[parent] = append(h.Children[parent], child)
+	h.Parents[child] = append(h.Parents[child], parent)
+}
+
+// descendants returns every task below id once, even when reachable through several parents.
+func (h *epicHierarchy) descendants(id int) []int {
+	seen := map[int]bool{id: true}
+	var result []int
+	stack := append([]int(nil), h.Children[id]...)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		result = append(result, current)
+		stack = append(stack, h.Children[current]...)
+	}
+	return result
+}
+
+func newEpicNode(task map[string]interface{}, loc *time.Location) *epicNode {
+	return &epicNode{
+		ID:            asInt(task["id"]),
+		Title:         asString(task["title"]),
+		Open:          asInt(task["is_active"]) == 1,
+		Score:         asFloat(task["score"]),
+		TimeEstimated: asFloat(task["time_estimated"]),
+		DueDate:       formatTimestamp(asInt(task["date_due"]), loc),
+		dateDue:       asInt(task["date_due"]),
+	}
+}
+
+// buildEpicHierarchy reads the parent/child links of every task of a project. Children
+// and parents living in other projects are fetched so their figures count as well.
+func (kc *kanboardClient) buildEpicHierarchy(ctx context.Context, projectID int) (*epicHierarchy, error) {
+	tasks, err := kc.projectTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	loc := kc.location(ctx)
+	hierarchy := &epicHierarchy{Nodes: map[int]*epicNode{}, Children: map[int][]int{}, Parents: map[int][]int{}}
+	for _, task := range tasks {
+		node := newEpicNode(task, loc)
+		hierarchy.Nodes[node.ID] = node
+	}
+
+	// A missing link or subtask would misstate the rollups and could let auto_close close
+	// an epic whose children are still open, so any failed lookup fails the whole tree.
+	links := make([][]map[string]interface{}, len(tasks))
+	errs := make([]error, len(tasks))
+	forEachConcurrent(len(tasks), 8, func(i int) {
+		result, err := kc.callKanboardAPI(ctx, "getAllTaskLinks", map[string]int{"task_id": asInt(tasks[i]["id"])})
+		if err != nil {
+			errs[i] = fmt.Errorf("links of task %d: %v", asInt(tasks[i]["id"]), err)
+			return
+		}
+		for _, item := range asList(result) {
+			links[i] = append(links[i], asMap(item))
+		}
+	})
+	if err := joinErrors(errs); err != nil {
+		return nil, err
+	}
+
+	var external []int
+	for i, task := range tasks {
+		taskID := asInt(task["id"])
+		for _, link := range links[i] {
+			label := strings.ToLower(asString(link["label"]))
+			if label != "is a child of" && label != "is a parent of" {
+				continue
+			}
+			otherID := asInt(link["opposite_task_id"])
+			if otherID == 0 {
+				otherID = asInt(link["task_id"])
+			}
+			if otherID == 0 || otherID == taskID {
+				continue
+			}
+			if _, known := hierarchy.Nodes[otherID]; !known {
+				hierarchy.Nodes[otherID] = &epicNode{ID: otherID, Title: asString(link["title"]), Open: asInt(link["is_active"]) == 1, External: true}
+				external = append(external, otherID)
+			}
+			if label == "is a child of" {
+				hierarchy.addChild(otherID, taskID)
+			} else {
+				hierarchy.addChild(taskID, otherID)
+			}
+		}
+	}
+	errs = make([]error, len(external))
+	forEachConcurrent(len(external), 8, func(i int) {
+		result, err := kc.callKanboardAPI(ctx, "getTask", map[string]int{"task_id": external[i]})
+		if err != nil {
+			errs[i] = fmt.Errorf("task %d: %v", external[i], err)
+			return
+		}
+		if len(asMap(result)) == 0 {
+			return
+		}
+		node := newEpicNode(asMap(result), loc)
+		node.External = true
+		*hierarchy.Nodes[external[i]] = *node
+	})
+
+	// Only tasks that are part of the hierarchy need their subtasks counted.
+	var linked []int
+	for id := range hierarchy.Nodes {
+		if len(hierarchy.Children[id]) > 0 || len(hierarchy.Parents[id]) > 0 {
+			linked = append(linked, id)
+		}
+	}
+	openSubtasks := make([]int, len(linked))
+	subtaskErrs := make([]error, len(linked))
+	forEachConcurrent(len(linked), 8, func(i int) {
+		result, err := kc.callKanboardAPI(ctx, "getAllSubtasks", map[string]int{"task_id": linked[i]})
+		if err != nil {
+			subtaskErrs[i] = fmt.Errorf("subtasks of task %d: %v", linked[i], err)
+			return
+		}
+		for _, item := range asList(result) {
+			if asInt(asMap(item)["status"]) != 2 {
+				openSubtasks[i]++
+			}
+		}
+	})
+	if err := joinErrors(append(errs, subtaskErrs...)); err != nil {
+		return nil, err
+	}
+	for i, id := range linked {
+		hierarchy.Nodes[id].OpenSubtasks = openSubtasks[i]
+	}
+	for id := range hierarchy.Children {
+		sort.Ints(hierarchy.Children[id])
+	}
+	return hierarchy, nil
+}
+
+// autoCloseEpics closes, bottom-up, every open parent of the project whose children are
+// all closed, so closing the last story of a feature can close its epic too.
+func (kc *kanboardClient) autoCloseEpics(ctx context.Context, hierarchy *epicHierarchy, roots []int) ([]int, error) {
+	closed := []int{}
+	done := map[int]bool{}
+	var visit func(id int, path map[int]bool) error
+	visit = func(id int, path map[int]bool) error {
+		if done[id] || path[id] {
+			return nil
+		}
+		path[id] = true
+		defer delete(path, id)
+		children := hierarchy.Children[id]
+		allClosed := true
+		for _, child := range children {
+			if err := visit(child, path); err != nil {
+				return err
+			}
+			if hierarchy.Nodes[child].Open {
+				allClosed = false
+			}
+		}
+		done[id] = true
+		node := hierarchy.Nodes[id]
+		if len(children) == 0 || !allClosed || !node.Open || node.External {
+			return nil
+		}
+		if _, err := kc.callKanboardAPI(ctx, "closeTask", map[string]int{"task_id": id}); err != nil {
+			return fmt.Errorf("failed to close task #%d: %v", id, err)
+		}
+		node.Open = false
+		closed = append(closed, id)
+		return nil
+	}
+	for _, root := range roots {
+		if err := visit(root, map[int]bool{}); err != nil {
+			return closed, err
+		}
+	}
+	return closed, nil
+}
+
+// epicTree copies the hierarchy below id into a tree of nodes with their rollups. A task
+// showing up again below itself (a link cycle) is not expanded a second time.
+func epicTree(hierarchy *epicHierarchy, id int, path map[int]bool, loc *time.Location) *epicNode {
+	node := *hierarchy.Nodes[id]
+	node.Children = nil
+	if path[id] {
+		return &node
+	}
+	path[id] = true
+	defer delete(path, id)
+	for _, child := range hierarchy.Children[id] {
+		node.Children = append(node.Children, epicTree(hierarchy, child, path, loc))
+	}
+	descendants := hierarchy.descendants(id)
+	if len(descendants) == 0 {
+		return &node
+	}
+	rollup := &epicRollup{Tasks: len(descendants), OpenSubtasks: node.OpenSubtasks}
+	latestDue := 0
+	for _, descendant := range descendants {
+		child := hierarchy.Nodes[descendant]
+		if !child.Open {
+			rollup.Closed++
+		}
+		rollup.OpenSubtasks += child.OpenSubtasks
+		rollup.Score += child.Score
+		rollup.TimeEstimated += child.TimeEstimated
+		if child.dateDue > latestDue {
+			latestDue = child.dateDue
+		}
+	}
+	rollup.Completion = roundTo(float64(rollup.Closed)/float64(rollup.Tasks)*100, 1)
+	rollup.Score = roundTo(rollup.Score, 2)
+	rollup.TimeEstimated = roundTo(rollup.TimeEstimated, 2)
+	rollup.LatestDueDate = formatTimestamp(latestDue, loc)
+	node.Rollup = rollup
+	return &node
+}
+
+func writeEpicTree(sb *strings.Builder, node *epicNode, depth int) {
+	check := " "
+	if !node.Open {
+		check = "x"
+	}
+	fmt.Fprintf(sb, "%s- [%s] #%d %s", strings.Repeat("  ", depth), check, node.ID, node.Title)
+	var details []string
+	if node.External {
+		details = append(details, "other project")
+	}
+	if rollup := node.Rollup; rollup != nil {
+		details = append(details, fmt.Sprintf("%g%% (%d/%d closed)", rollup.Completion, rollup.Closed, rollup.Tasks))
+		if rollup.OpenSubtasks > 0 {
+			details = append(details, fmt.Sprintf("%d open subtasks", rollup.OpenSubtasks))
+		}
+		if rollup.Score > 0 {
+			details = append(details, fmt.Sprintf("score %g", rollup.Score))
+		}
+		if rollup.TimeEstimated > 0 {
+			details = append(details, fmt.Sprintf("%gh estimated", rollup.TimeEstimated))
+		}
+		if rollup.LatestDueDate != "" {
+			details = append(details, "latest due "+rollup.LatestDueDate[:10])
+		}
+	} else {
+		if node.OpenSubtasks > 0 {
+			details = append(details, fmt.Sprintf("%d open subtasks", node.OpenSubtasks))
+		}
+		if node.Score > 0 {
+			details = append(details, fmt.Sprintf("score %g", node.Score))
+		}
+		if node.TimeEstimated > 0 {
+			details = append(details, fmt.Sprintf("%gh estimated", node.TimeEstimated))
+		}
+		if node.DueDate != "" {
+			details = append(details, "due "+node.DueDate[:10])
+		}
+	}
+	if len(details) > 0 {
+		sb.WriteString(" — " + strings.Join(details, " · "))
+	}
+	sb.WriteString("\n")
+	for _, child := range node.Children {
+		writeEpicTree(sb, child, depth+1)
+	}
+}
+
+func (kc *kanboardClient) epicTreeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID, err := request.RequireInt("project_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	rootID := request.GetInt("task_id", 0)
+	format := request.GetString("format", "markdown")
+	if format != "markdown" && format != "json" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported format '%s' (expected markdown or json)", format)), nil
+	}
+
+	hierarchy, err := kc.buildEpicHierarchy(ctx, projectID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var roots []int
+	if rootID != 0 {
+		if _, ok := hierarchy.Nodes[rootID]; !ok || len(hierarchy.Children[rootID]) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("task #%d has no children in project %d", rootID, projectID)), nil
+		}
+		roots = []int{rootID}
+	} else {
+		for id, children := range hierarchy.Children {
+			if len(children) > 0 && len(hierarchy.Parents[id]) == 0 {
+				roots = append(roots, id)
+			}
+		}
+		sort.Ints(roots)
+	}
+
+	autoClosed := []int{}
+	if request.GetBool("auto_close", false) {
+		autoClosed, err = kc.autoCloseEpics(ctx, hierarchy, roots)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%v (already closed: %v)", err, autoClosed)), nil
+		}
+	}
+
+	loc := kc.location(ctx)
+	trees := []*epicNode{}
+	for _, root := range roots {
+		trees = append(trees, epicTree(hierarchy, root, map[int]bool{}, loc))
+	}
+
+	if format == "json" {
+		resultBytes, err := json.MarshalIndent(map[string]interface{}{
+			"project_id":  projectID,
+			"epics":       trees,
+			"auto_closed": autoClosed,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(resultBytes)), nil
+	}
+
+	var sb strings.Builder
+	if len(trees) == 0 {
+		fmt.Fprintf(&sb, "No parent/child links in project %d.\n", projectID)
+	}
+	for _, tree := range trees {
+		writeEpicTree(&sb, tree, 0)
+	}
+	if len(autoClosed) > 0 {
+		ids := make([]string, len(autoClosed))
+		for i, id := range autoClosed {
+			ids[i] = fmt.Sprintf("#%d", id)
+		}
+		fmt.Fprintf(&sb, "\nAuto-closed: %s\n", strings.Join(ids, ", "))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// gitCommit is a commit read from `git log`.
+type gitCommit struct {
+	Hash    string
+	Author  string
+	Email   string
+	Date    time.Time
+	Message string
+}
+
+// gitScanOptions configures scanGitCommits; it is filled from tool arguments or from the
+// scan-git command line flags.
+type gitScanOptions struct {
+	RepoPath  string
+	Revision  string
+	Since     string
+	MaxCount  int
+	ProjectID int
+	Prefix    string
+	CommitURL string
+	User      string
+	Close     bool
+	DryRun    bool
+}
+
+// gitTaskRef is a task referenced by a commit and what was done about it.
+type gitTaskRef struct {
+	Commit    string `json:"commit"`
+	Subject   string `json:"subject"`
+	TaskID    int    `json:"task_id"`
+	Reference string `json:"reference"`
+	Fixes     bool   `json:"fixes"`
+	Commented bool   `json:"commented"`
+	Linked    bool   `json:"linked"`
+	Closed    bool   `json:"closed"`
+	Skipped   string `json:"skipped,omitempty"`
+}
+
+// gitScanResult is the outcome of a repository scan.
+type gitScanResult struct {
+	Repository string       `json:"repository"`
+	Commits    int          `json:"commits_scanned"`
+	CommitURL  string       `json:"commit_url,omitempty"`
+	DryRun     bool         `json:"dry_run,omitempty"`
+	References []gitTaskRef `json:"references"`
+}
+
+var (
+	gitRefPattern    = regexp.MustCompile(`(?:^|[^\w&/])(#(\d+)|([A-Za-z][A-Za-z0-9]*)-(\d+))\b`)
+	gitFixesPattern  = regexp.MustCompile(`(?i)\b(?:fix(?:e[sd])?|close[sd]?|resolve[sd]?)\s*:?\s+$`)
+	gitRemotePattern = regexp.MustCompile(`^(?:https?://|ssh://)?(?:[^@/]+@)?([^:/]+)(?::\d+)?[:/](.+?)(?:\.git)?/?$`)
+)
+
+// gitLog reads the commits of a repository with the git command line.
+func gitLog(ctx context.Context, options gitScanOptions) ([]gitCommit, error) {
+	// The revision comes from tool arguments: never let it be read as a git option such
+	// as --output=<file>.
+	if strings.HasPrefix(options.Revision, "-") {
+		return nil, fmt.Errorf("invalid revision '%s'", options.Revision)
+	}
+	inside, err := exec.CommandContext(ctx, "git", "-C", options.RepoPath, "rev-parse", "--is-inside-work-tree").Output()
+	if err != nil || strings.TrimSpace(string(inside)) != "true" {
+		return nil, fmt.Errorf("'%s' is not a git work tree", options.RepoPath)
+	}
+
+	args := []string{"-C", options.RepoPath, "log", "--format=%H%x1f%an%x1f%ae%x1f%at%x1f%B%x1e"}
+	if options.MaxCount > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", options.MaxCount))
+	}
+	if options.Since != "" {
+		args = append(args, "--since="+options.Since)
+	}
+	if options.Revision != "" {
+		args = append(args, "--end-of-options", options.Revision, "--")
+	}
+	output, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("git log failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git log failed: %v", err)
+	}
+
+	var commits []gitCommit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		timestamp, _ := strconv.ParseInt(fields[3], 10, 64)
+		commits = append(commits, gitCommit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Date:    time.Unix(timestamp, 0),
+			Message: strings.TrimSpace(fields[4]),
+		})
+	}
+	// Oldest first, so comments end up in the order the work happened.
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
+
+// gitCommitURL derives a commit URL template ("{hash}" placeholder) from the origin remote
+// of the repository, e.g. git@github.com:acme/api.git → https://github.com/acme/api/commit/{hash}.
+func gitCommitURL(ctx context.Context, repoPath string) string {
+	output, err := exec.CommandContext(ctx, "git", "-C", repoPath, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	match := gitRemotePattern.FindStringSubmatch(strings.TrimSpace(string(output)))
+	if match == nil {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/%s/commit/{hash}", match[1], match[2])
+}
+
+// commitReference is a task reference found in a commit message. Bare is set for "#123"
+// references, which may just as well point at an issue or pull request of the git host.
+type commitReference struct {
+	Text   string
+	TaskID int
+	Key    string
+	Fixes  bool
+	Bare   bool
+}
+
+// commitReferences finds "#123", "<prefix>-123" and other "ABC-123" style task references
+// in a commit message. A reference preceded by fix/fixes/close/resolve... asks to close the task.
+func commitReferences(message, prefix string) []commitReference {
+	var refs []commitReference
+	seen := map[string]int{}
+	for _, match := range gitRefPattern.FindAllStringSubmatchIndex(message, -1) {
+		text := message[match[2]:match[3]]
+		ref := commitReference{Text: text, Key: text}
+		if match[4] >= 0 {
+			ref.TaskID, _ = strconv.Atoi(message[match[4]:match[5]])
+			ref.Bare = true
+		} else if key := message[match[6]:match[7]]; strings.EqualFold(key, prefix) {
+			ref.TaskID, _ = strconv.Atoi(message[match[8]:match[9]])
+		} else if key != strings.ToUpper(key) {
+			// Only upper-case keys are task references; skips utf-8, sha-256 and the like.
+			continue
+		}
+		if ref.TaskID != 0 {
+			ref.Key = "#" + strconv.Itoa(ref.TaskID)
+		}
+		ref.Fixes = gitFixesPattern.MatchString(message[:match[2]])
+		if i, ok := seen[ref.Key]; ok {
+			refs[i].Fixes = refs[i].Fixes || ref.Fixes
+			refs[i].Bare = refs[i].Bare && ref.Bare
+			continue
+		}
+		seen[ref.Key] = len(refs)
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// scanGitCommits comments on, links and optionally closes the tasks referenced by the
+// commits of a repository. The full commit hash in a task's comments marks the commit as
+// already handled, so rescanning the same history changes nothing. Bare "#123" references
+// are only trusted within a project, where the task must belong to it.
+func (kc *kanboardClient) scanGitCommits(ctx context.Context, options gitScanOptions) (*gitScanResult, error) {
+	if options.RepoPath == "" {
+		options.RepoPath = "."
+	}
+	if options.Prefix == "" {
+		options.Prefix = "KB"
+	}
+	commits, err := gitLog(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	if options.CommitURL == "" {
+		options.CommitURL = gitCommitURL(ctx, options.RepoPath)
+	}
+	result := &gitScanResult{Repository: options.RepoPath, Commits: len(commits), CommitURL: options.CommitURL, DryRun: options.DryRun, References: []gitTaskRef{}}
+
+	defaultUser := 0
+	if options.User != "" {
+		if defaultUser, err = kc.resolveUserID(ctx, options.User); err != nil {
+			return nil, err
+		}
+	}
+	authors := map[string]int{}
+	authorID := func(commit gitCommit) int {
+		if defaultUser != 0 {
+			return defaultUser
+		}
+		if id, ok := authors[commit.Email]; ok {
+			return id
+		}
+		id := 0
+		if users, err := kc.callKanboardAPICached(ctx, "getAllUsers", nil); err == nil {
+			for _, item := range asList(users) {
+				user := asMap(item)
+				if (commit.Email != "" && strings.EqualFold(asString(user["email"]), commit.Email)) || strings.EqualFold(asString(user["name"]), commit.Author) || strings.EqualFold(asString(user["username"]), commit.Author) {
+					id = asInt(user["id"])
+					break
+				}
+			}
+		}
+		if id == 0 {
+			id, _ = kc.resolveUserID(ctx, "me")
+		}
+		authors[commit.Email] = id
+		return id
+	}
+
+	tasks := map[string]map[string]interface{}{}
+	lookup := func(ref commitReference) map[string]interface{} {
+		if task, ok := tasks[ref.Key]; ok {
+			return task
+		}
+		var task map[string]interface{}
+		if ref.TaskID != 0 {
+			if found, err := kc.callKanboardAPI(ctx, "getTask", map[string]int{"task_id": ref.TaskID}); err == nil {
+				task = asMap(found)
+			}
+		} else if options.ProjectID != 0 {
+			if found, err := kc.callKanboardAPI(ctx, "getTaskByReference", map[string]interface{}{"project_id": options.ProjectID, "reference": ref.Text}); err == nil {
+				task = asMap(found)
+			}
+		}
+		if task != nil && options.ProjectID != 0 && asInt(task["project_id"]) != options.ProjectID {
+			task = nil
+		}
+		tasks[ref.Key] = task
+		return task
+	}
+
+	for _, commit := range commits {
+		subject := strings.SplitN(commit.Message, "\n", 2)[0]
+		url := strings.ReplaceAll(options.CommitURL, "{hash}", commit.Hash)
+		for _, ref := range commitReferences(commit.Message, options.Prefix) {
+			if ref.Bare && options.ProjectID == 0 {
+				result.References = append(result.References, gitTaskRef{Commit: commit.Hash[:7], Subject: subject, TaskID: ref.TaskID, Reference: ref.Text, Fixes: ref.Fixes, Skipped: "bare reference needs project_id"})
+				continue
+			}
+			task := lookup(ref)
+			if task == nil {
+				// "ABC-123" outside a project and unknown "#123"s are most likely not tasks.
+				if ref.TaskID != 0 {
+					result.References = append(result.References, gitTaskRef{Commit: commit.Hash[:7], Subject: subject, TaskID: ref.TaskID, Reference: ref.Text, Fixes: ref.Fixes, Skipped: "task not found"})
+				}
+				continue
+			}
+			taskID := asInt(task["id"])
+			entry := gitTaskRef{Commit: commit.Hash[:7], Subject: subject, TaskID: taskID, Reference: ref.Text, Fixes: ref.Fixes}
+
+			comments, err := kc.callKanboardAPI(ctx, "getAllComments", map[string]int{"task_id": taskID})
+			if err != nil {
+				return result, fmt.Errorf("failed to get comments of task #%d: %v", taskID, err)
+			}
+			handled := false
+			for _, item := range asList(comments) {
+				if strings.Contains(asString(asMap(item)["comment"]), commit.Hash) {
+					handled = true
+					break
+				}
+			}
+			if handled {
+				entry.Skipped = "already recorded"
+				result.References = append(result.References, entry)
+				continue
+			}
+
+			linked := url == ""
+			if !linked {
+				links, err := kc.callKanboardAPI(ctx, "getAllExternalTaskLinks", map[string]int{"task_id": taskID})
+				if err != nil {
+					return result, fmt.Errorf("failed to get external links of task #%d: %v", taskID, err)
+				}
+				for _, item := range asList(links) {
+					if asString(asMap(item)["url"]) == url {
+						linked = true
+						break
+					}
+				}
+			}
+
+			if options.DryRun {
+				entry.Commented = true
+				entry.Linked = !linked
+				entry.Closed = ref.Fixes && options.Close && asInt(task["is_active"]) == 1
+				result.References = append(result.References, entry)
+				continue
+			}
+
+			if !linked {
+				if _, err := kc.callKanboardAPI(ctx, "createExternalTaskLink", map[string]interface{}{
+					"task_id":    taskID,
+					"url":        url,
+					"dependency": "related",
+					"type":       "weblink",
+					"title":      fmt.Sprintf("Commit %s: %s", commit.Hash[:7], subject),
+				}); err != nil {
+					return result, fmt.Errorf("failed to link commit %s to task #%d: %v", commit.Hash[:7], taskID, err)
+				}
+				entry.Linked = true
+			}
+
+			commitText := fmt.Sprintf("`%s`", commit.Hash[:7])
+			if url != "" {
+				commitText = fmt.Sprintf("[%s](%s)", commit.Hash[:7], url)
+			}
+			content := fmt.Sprintf("Referenced in commit %s by %s on %s:\n\n> %s\n\n<!-- %s -->",
+				commitText, commit.Author, commit.Date.Format("2006-01-02 15:04"), strings.ReplaceAll(commit.Message, "\n", "\n> "), commit.Hash)
+			if _, err := kc.callKanboardAPI(ctx, "createComment", map[string]interface{}{
+				"task_id": taskID,
+				"user_id": authorID(commit),
+				"content": content,
+			}); err != nil {
+				return result, fmt.Errorf("failed to comment on task #%d: %v", taskID, err)
+			}
+			entry.Commented = true
+
+			if ref.Fixes && options.Close && asInt(task["is_active"]) == 1 {
+				if _, err := kc.callKanboardAPI(ctx, "closeTask", map[string]int{"task_id": taskID}); err != nil {
+					return result, fmt.Errorf("failed to close task #%d: %v", taskID, err)
+				}
+				task["is_active"] = "0"
+				entry.Closed = true
+			}
+			result.References = append(result.References, entry)
+		}
+	}
+	return result, nil
+}
+
+func (kc *kanboardClient) scanGitCommitsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	options := gitScanOptions{
+		RepoPath:  request.GetString("repo_path", "."),
+		Revision:  request.GetString("revision", ""),
+		Since:     request.GetString("since", ""),
+		MaxCount:  request.GetInt("max_count", 100),
+		ProjectID: request.GetInt("project_id", 0),
+		Prefix:    request.GetString("prefix", "KB"),
+		CommitURL: request.GetString("commit_url", ""),
+		User:      request.GetString("user", ""),
+		Close:     request.GetBool("close", false),
+		DryRun:    request.GetBool("dry_run", false),
+	}
+	result, err := kc.scanGitCommits(ctx, options)
+	if err != nil && result == nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err != nil {
+		resultBytes, _ := json.MarshalIndent(result, "", "  ")
+		return mcp.NewToolResultError(fmt.Sprintf("%v\n\nDone before the error:\n%s", err, resultBytes)), nil
+	}
+	resultBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+// runScanGit implements the scan-git subcommand, e.g. from a post-merge hook or CI job:
+//
+//	kanboard-mcp scan-git -repo . -project 3 -since "1 week ago"
+func runScanGit(kc *kanboardClient, args []string) int {
+	flags := flag.NewFlagSet("scan-git", flag.ContinueOnError)
+	options := gitScanOptions{}
+	flags.StringVar(&options.RepoPath, "repo", ".", "path of the git repository")
+	flags.StringVar(&options.Revision, "rev", "", "revision range to scan, e.g. v1.2.0..HEAD")
+	flags.StringVar(&options.Since, "since", "", "only scan commits more recent than this (git date syntax)")
+	flags.IntVar(&options.MaxCount, "max-count", 100, "maximum number of commits to scan")
+	flags.IntVar(&options.ProjectID, "project", 0, "only update tasks of this project and resolve ABC-123 and bare #123 task references in it")
+	flags.StringVar(&options.Prefix, "prefix", "KB", "prefix of task ID references such as KB-123")
+	flags.StringVar(&options.CommitURL, "commit-url", "", "commit URL template with a {hash} placeholder (default: derived from the origin remote)")
+	flags.StringVar(&options.User, "user", "", "Kanboard user to comment as (default: the commit author when known)")
+	flags.BoolVar(&options.Close, "close", false, "close tasks referenced with fixes/closes/resolves")
+	flags.BoolVar(&options.DryRun, "dry-run", false, "only report what would be done")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	result, err := kc.scanGitCommits(context.Background(), options)
+	if result != nil {
+		for _, ref := range result.References {
+			var actions []string
+			if ref.Commented {
+				actions = append(actions, "commented")
+			}
+			if ref.Linked {
+				actions = append(actions, "linked")
+			}
+			if ref.Closed {
+				actions = append(actions, "closed")
+			}
+			if ref.Skipped != "" {
+				actions = append(actions, "skipped: "+ref.Skipped)
+			}
+			fmt.Printf("%s #%d %s\n", ref.Commit, ref.TaskID, strings.Join(actions, ", "))
+		}
+		fmt.Printf("%d commit(s) scanned, %d task reference(s)\n", result.Commits, len(result.References))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan-git: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// webhookEventsURI is the MCP resource listing the latest Kanboard webhook events.
+const webhookEventsURI = "kanboard://events"
+
+// webhookEvent is a Kanboard webhook notification as recorded in the event log.
+type webhookEvent struct {
+	ReceivedAt string                 `json:"received_at"`
+	EventName  string                 `json:"event_name"`
+	ProjectID  int                    `json:"project_id,omitempty"`
+	TaskID     int                    `json:"task_id,omitempty"`
+	Author     string                 `json:"author,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+// maxWebhookEvents is the number of events the event log keeps.
+const maxWebhookEvents = 1000
+
+// eventLog keeps the latest webhook events in memory and appends them to a JSON lines file.
+// The file is compacted to the latest maxWebhookEvents events once it holds twice as many,
+// and it is only read again when another process has written to it.
+type eventLog struct {
+	path   string
+	mu     sync.Mutex
+	events []webhookEvent
+	lines  int
+	size   int64
+	mod    time.Time
+}
+
+func newEventLog(path string) *eventLog {
+	return &eventLog{path: path}
+}
+
+// load reads the file when its size or modification time changed since it was last seen.
+// The caller holds el.mu.
+func (el *eventLog) load() error {
+	info, err := os.Stat(el.path)
+	if os.IsNotExist(err) {
+		el.events, el.lines, el.size, el.mod = nil, 0, 0, time.Time{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() == el.size && info.ModTime().Equal(el.mod) {
+		return nil
+	}
+	events, err := readWebhookEvents(el.path)
+	if err != nil {
+		return err
+	}
+	el.lines = len(events)
+	if len(events) > maxWebhookEvents {
+		events = events[len(events)-maxWebhookEvents:]
+	}
+	el.events = events
+	el.size, el.mod = info.Size(), info.ModTime()
+	return nil
+}
+
+// add appends an event to the file and to the in-memory events.
+func (el *eventLog) add(event webhookEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	if err := el.load(); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(el.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	el.events = append(el.events, event)
+	if len(el.events) > maxWebhookEvents {
+		el.events = el.events[len(el.events)-maxWebhookEvents:]
+	}
+	el.lines++
+	if el.lines > 2*maxWebhookEvents {
+		if err := el.compact(); err != nil {
+			return err
+		}
+	}
+	if info, err := os.Stat(el.path); err == nil {
+		el.size, el.mod = info.Size(), info.ModTime()
+	}
+	return nil
+}
+
+// compact rewrites the file with the in-memory events. The caller holds el.mu.
+func (el *eventLog) compact() error {
+	var data []byte
+	for _, event := range el.events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	tmp := el.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, el.path); err != nil {
+		return err
+	}
+	el.lines = len(el.events)
+	return nil
+}
+
+// recent returns a copy of the latest events, oldest first.
+func (el *eventLog) recent() ([]webhookEvent, error) {
+	if el == nil {
+		return nil, nil
+	}
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	if err := el.load(); err != nil {
+		return nil, err
+	}
+	return append([]webhookEvent(nil), el.events...), nil
+}
+
+// webhookReceiver accepts the webhooks Kanboard posts to "<url>?token=<webhook token>",
+// appends them to the event log and notifies the connected MCP clients.
+type webhookReceiver struct {
+	token  string
+	kc     *kanboardClient
+	server *server.MCPServer
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(wr.token)) != 1 {
+		http.Error(w, "invalid webhook token", http.StatusUnauthorized)
+		return
+	}
+	var payload struct {
+		EventName   string                 `json:"event_name"`
+		EventData   map[string]interface{} `json:"event_data"`
+		EventAuthor string                 `json:"event_author"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&payload); err != nil || payload.EventName == "" {
+		http.Error(w, "invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+
+	event := webhookEvent{
+		ReceivedAt: time.Now().UTC().Format(time.RFC3339),
+		EventName:  payload.EventName,
+		Author:     payload.EventAuthor,
+		Data:       payload.EventData,
+	}
+	task := asMap(payload.EventData["task"])
+	event.TaskID = asInt(payload.EventData["task_id"])
+	if event.TaskID == 0 {
+		event.TaskID = asInt(task["id"])
+	}
+	event.ProjectID = asInt(task["project_id"])
+	if event.ProjectID == 0 {
+		event.ProjectID = asInt(payload.EventData["project_id"])
+	}
+
+	if err := wr.kc.events.add(event); err != nil {
+		http.Error(w, fmt.Sprintf("failed to record event: %v", err), http.StatusInternalServerError)
+		return
+	}
+	// Whatever changed, cached lookups may now be stale.
+	wr.kc.cache.clear()
+
+	wr.server.SendNotificationToAllClients("notifications/message", map[string]any{
+		"level":  "info",
+		"logger": "kanboard.webhook",
+		"data":   event,
+	})
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+// readWebhookEvents returns the events of the event log, oldest first. A missing log
+// simply has no events yet.
+func readWebhookEvents(path string) ([]webhookEvent, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var events []webhookEvent
+	for _, line := range strings.Split(string(data), "\n") {
+		var event webhookEvent
+		if strings.TrimSpace(line) == "" || json.Unmarshal([]byte(line), &event) != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// bearerAuth rejects requests whose Authorization header does not carry the token.
+type bearerAuth struct {
+	token string
+	next  http.Handler
+}
+
+func (ba *bearerAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(ba.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	ba.next.ServeHTTP(w, r)
+}
+
+// isLoopbackHost reports whether host only accepts local connections.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serveHTTP serves MCP over streamable HTTP on /mcp and, when a webhook token is
+// configured, Kanboard webhooks on /webhook.
+func serveHTTP(s *server.MCPServer, kc *kanboardClient, addr, mcpToken, webhookToken string) error {
+	mux := http.NewServeMux()
+	if mcpToken != "" {
+		mux.Handle("/mcp", &bearerAuth{token: mcpToken, next: server.NewStreamableHTTPServer(s)})
+	} else {
+		// Without a token anyone who can reach /mcp acts with the Kanboard credentials, so
+		// only local clients are accepted.
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return fmt.Errorf("invalid KANBOARD_MCP_HTTP_ADDR '%s': %v", addr, err)
+		}
+		if host == "" {
+			addr = net.JoinHostPort("127.0.0.1", port)
+		} else if !isLoopbackHost(host) {
+			return fmt.Errorf("KANBOARD_MCP_HTTP_TOKEN must be set to listen on the non-loopback address '%s'", addr)
+		}
+		fmt.Fprintln(os.Stderr, "KANBOARD_MCP_HTTP_TOKEN is not set, listening on the loopback interface only")
+		mux.Handle("/mcp", server.NewStreamableHTTPServer(s))
+	}
+	if webhookToken != "" {
+		mux.Handle("/webhook", &webhookReceiver{token: webhookToken, kc: kc, server: s})
+	} else {
+		fmt.Fprintln(os.Stderr, "KANBOARD_WEBHOOK_TOKEN is not set, the /webhook endpoint is disabled")
+	}
+	fmt.Fprintf(os.Stderr, "Listening on %s (MCP on /mcp)\n", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+func (kc *kanboardClient) webhookEventsResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	events, err := kc.events.recent()
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 100 {
+		events = events[len(events)-100:]
+	}
+	if events == nil {
+		events = []webhookEvent{}
+	}
+	resultBytes, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: webhookEventsURI, MIMEType: "application/json", Text: string(resultBytes)},
+	}, nil
+}
+
+func (kc *kanboardClient) getWebhookEventsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if kc.events == nil {
+		return mcp.NewToolResultError("No event log: webhook events are only recorded in HTTP mode (KANBOARD_MCP_HTTP_ADDR); set KANBOARD_EVENT_LOG to read the log of an HTTP server"), nil
+	}
+	events, err := kc.events.recent()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read the event log: %v", err)), nil
+	}
+	eventName := request.GetString("event_name", "")
+	projectID := request.GetInt("project_id", 0)
+	taskID := request.GetInt("task_id", 0)
+	limit := request.GetInt("limit", 50)
+	var since time.Time
+	if value := request.GetString("since", ""); value != "" {
+		if since, _, err = parseDateExpr(value, time.Now(), kc.location(ctx), nil); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid since: %v", err)), nil
+		}
+	}
+
+	matching := []webhookEvent{}
+	for _, event := range events {
+		if eventName != "" && !strings.HasPrefix(event.EventName, eventName) {
+			continue
+		}
+		if (projectID != 0 && event.ProjectID != projectID) || (taskID != 0 && event.TaskID != taskID) {
+			continue
+		}
+		if !since.IsZero() {
+			if received, err := time.Parse(time.RFC3339, event.ReceivedAt); err != nil || received.Before(since) {
+				continue
+			}
+		}
+		matching = append(matching, event)
+	}
+	if limit > 0 && len(matching) > limit {
+		matching = matching[len(matching)-limit:]
+	}
+	resultBytes, err := json.MarshalIndent(matching, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultBytes)), nil
+}
+
+// standupEntry is a task line of a standup digest.
+type standupEntry struct {
+	TaskID  int    `json:"task_id"`
+	Title   string `json:"title"`
+	Project string `json:"project,omitempty"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// standupDigest is what one person did and has on their plate.
+type standupDigest struct {
+	UserID     int            `json:"user_id"`
+	User       string         `json:"user"`
+	Done       []standupEntry `json:"done"`
+	InProgress []standupEntry `json:"in_progress"`
+	Blocked    []standupEntry `json:"blocked"`
+	Overdue    []standupEntry `json:"overdue"`
+	DueSoon    []standupEntry `json:"due_soon"`
+}
+
+func (d *standupDigest) empty() bool {
+	return len(d.Done)+len(d.InProgress)+len(d.Blocked)+len(d.Overdue)+len(d.DueSoon) == 0
+}
+
+// standupActivity describes what an event did to its task, or "" for events that don't
+// count as progress.
+func standupActivity(event map[string]interface{}) string {
+	switch asString(event["event_name"]) {
+	case "task.create":
+		return "created"
+	case "task.update":
+		return "updated"
+	case "task.move.column":
+		if column := asString(eventField(event, "column_title")); column != "" {
+			return "moved to " + column
+		}
+		return "moved"
+	case "task.assignee_change":
+		return "picked up"
+	case "comment.create":
+		return "commented"
+	case "subtask.create":
+		return "added subtask " + asString(asMap(event["subtask"])["title"])
+	case "subtask.update":
+		subtask := asMap(event["subtask"])
+		if asInt(subtask["status"]) == 2 {
+			return "finished subtask " + asString(subtask["title"])
+		}
+		if asInt(subtask["status"]) == 1 {
+			return "started subtask " + asString(subtask["title"])
+		}
+		return "updated subtask " + asString(subtask["title"])
+	case "task.file.create":
+		return "attached a file"
+	}
+	return ""
+}
+
+// previousWorkday returns the start of the last working day before now (Friday on Mondays).
+func previousWorkday(now time.Time) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -1)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// buildStandupDigest sorts a user's events since from and their open tasks into a digest.
+// blockers lists the open tasks blocking each task.
+func buildStandupDigest(userID int, userName string, events, openTasks []map[string]interface{}, from, now time.Time, dueDays int, blockers map[int][]int, projects map[int]string) standupDigest {
+	digest := standupDigest{UserID: userID, User: userName, Done: []standupEntry{}, InProgress: []standupEntry{}, Blocked: []standupEntry{}, Overdue: []standupEntry{}, DueSoon: []standupEntry{}}
+	entry := func(task map[string]interface{}, detail string) standupEntry {
+		project := asString(task["project_name"])
+		if project == "" {
+			project = projects[asInt(task["project_id"])]
+		}
+		return standupEntry{TaskID: asInt(task["id"]), Title: asString(task["title"]), Project: project, Detail: detail}
+	}
+
+	// What the user did in the window, per task in order of first activity.
+	var touched []int
+	activities := map[int][]string{}
+	snapshots := map[int]map[string]interface{}{}
+	closed := map[int]bool{}
+	for _, event := range events {
+		at := time.Unix(int64(asInt(event["date_creation"])), 0)
+		if asInt(event["creator_id"]) != userID || at.Before(from) || at.After(now) {
+			continue
+		}
+		taskID := asInt(event["task_id"])
+		if taskID == 0 {
+			continue
+		}
+		if _, ok := activities[taskID]; !ok {
+			touched = append(touched, taskID)
+			activities[taskID] = []string{}
+		}
+		if snapshot := asMap(event["task"]); len(snapshot) > 0 {
+			snapshots[taskID] = snapshot
+		}
+		switch asString(event["event_name"]) {
+		case "task.close":
+			closed[taskID] = true
+		case "task.open":
+			closed[taskID] = false
+		default:
+			if activity := standupActivity(event); activity != "" {
+				activities[taskID] = append(activities[taskID], activity)
+			}
+		}
+	}
+
+	open := map[int]map[string]interface{}{}
+	for _, task := range openTasks {
+		open[asInt(task["id"])] = task
+	}
+	for _, taskID := range touched {
+		if closed[taskID] {
+			task := snapshots[taskID]
+			if task == nil {
+				task = map[string]interface{}{"id": taskID}
+			}
+			digest.Done = append(digest.Done, entry(task, "closed"))
+			continue
+		}
+		task, isOpen := open[taskID]
+		if !isOpen || len(blockers[taskID]) > 0 {
+			// Finished subtasks of a task that isn't assigned to the user still count as done.
+			var finished []string
+			for _, activity := range activities[taskID] {
+				if strings.HasPrefix(activity, "finished subtask") {
+					finished = append(finished, activity)
+				}
+			}
+			if len(finished) > 0 && snapshots[taskID] != nil {
+				digest.Done = append(digest.Done, entry(snapshots[taskID], strings.Join(finished, ", ")))
+			}
+			continue
+		}
+		digest.InProgress = append(digest.InProgress, entry(task, strings.Join(uniqueStrings(activities[taskID]), ", ")))
+	}
+
+	soon := now.AddDate(0, 0, dueDays)
+	sort.SliceStable(openTasks, func(i, j int) bool {
+		return asInt(openTasks[i]["date_due"]) < asInt(openTasks[j]["date_due"])
+	})
+	for _, task := range openTasks {
+		taskID := asInt(task["id"])
+		if ids := blockers[taskID]; len(ids) > 0 {
+			refs := make([]string, len(ids))
+			for i, id := range ids {
+				refs[i] = fmt.Sprintf("#%d", id)
+			}
+			digest.Blocked = append(digest.Blocked, entry(task, "blocked by "+strings.Join(refs, ", ")))
+		} else if _, done := activities[taskID]; !done && asInt(task["date_started"]) > 0 && asInt(task["date_started"]) <= int(now.Unix()) {
+			digest.InProgress = append(digest.InProgress, entry(task, "started "+time.Unix(int64(asInt(task["date_started"])), 0).In(now.Location()).Format("2006-01-02")))
+		}
+		due := asInt(task["date_due"])
+		if due <= 0 {
+			continue
+		}
+		dueAt := time.Unix(int64(due), 0).In(now.Location())
+		if dueAt.Before(now) {
+			digest.Overdue = append(digest.Overdue, entry(task, fmt.Sprintf("due %s (%d days ago)", dueAt.Format("2006-01-02"), int(now.Sub(dueAt).Hours()/24))))
+		} else if dueAt.Before(soon) {
+			digest.DueSoon = append(digest.DueSoon, entry(task, "due "+dueAt.Format("2006-01-02 15:04")))
+		}
+	}
+	return digest
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// taskBlockers returns, for each task, the open tasks that block it, and a warning for
+// every task whose links could not be read.
+func (kc *kanboardClient) taskBlockers(ctx context.Context, tasks []map[string]interface{}) (map[int][]int, []string) {
+	blockers := make([][]int, len(tasks))
+	errs := make([]error, len(tasks))
+	forEachConcurrent(len(tasks), 8, func(i int) {
+		result, err := kc.callKanboardAPI(ctx, "getAllTaskLinks", map[string]int{"task_id": asInt(tasks[i]["id"])})
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		for _, item := range asList(result) {
+			link := asMap(item)
+			before, ok := dependencyLabels[strings.ToLower(asString(link["label"]))]
+			if ok && before && asInt(link["is_active"]) == 1 {
+				blockers[i] = append(blockers[i], asInt(link["task_id"]))
+			}
+		}
+		sort.Ints(blockers[i])
+	})
+	result := map[int][]int{}
+	var warnings []string
+	for i, task := range tasks {
+		if errs[i] != nil {
+			warnings = append(warnings, fmt.Sprintf("blockers of task #%d are unknown: %v", asInt(task["id"]), errs[i]))
+		}
+		if len(blockers[i]) > 0 {
+			result[asInt(task["id"])] = blockers[i]
+		}
+	}
+	return result, warnings
+}
+
+func (kc *kanboardClient) standupDigestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := request.GetInt("project_id", 0)
+	user := request.GetString("user", "")
+	if user == "" && projectID == 0 {
+		user = "me"
+	}
+	dueDays := request.GetInt("due_days", 3)
+	loc := kc.location(ctx)
+	now := time.Now().In(loc)
+	from := previousWorkday(now)
+	if value := request.GetString("since", ""); value != "" {
+		parsed, _, err := parseDateExpr(value, now, loc, nil)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid since: %v", err)), nil
+		}
+		from = parsed
+	}
+
+	projects, err := kc.visibleProjects(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get projects: %v", err)), nil
+	}
+	resolver := kc.newNameResolver(ctx)
+	userName := func(userID int) string {
+		if name := resolver.userName(ctx, userID); name != "" {
+			return name
+		}
+		return fmt.Sprintf("User #%d", userID)
+	}
+	var digests []standupDigest
+	var warnings []string
+
+	if strings.EqualFold(user, "me") && projectID == 0 {
+		// The current user's own view: activity stream, dashboard and overdue tasks.
+		userID, err := kc.resolveUserID(ctx, "me")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		stream, err := kc.callKanboardAPI(ctx, "getMyActivityStream", nil)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get activity stream: %v", err)), nil
+		}
+		dashboard, err := kc.callKanboardAPI(ctx, "getMyDashboard", nil)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get dashboard: %v", err)), nil
+		}
+		overdue, err := kc.callKanboardAPI(ctx, "getMyOverdueTasks", nil)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get overdue tasks: %v", err)), nil
+		}
+
+		var events, openTasks []map[string]interface{}
+		for _, item := range asList(stream) {
+			events = append(events, asMap(item))
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return asInt(events[i]["date_creation"]) < asInt(events[j]["date_creation"])
+		})
+		// Older Kanboard versions return {"projects", "tasks", "subtasks"} instead of a task list.
+		dashboardTasks := asList(dashboard)
+		if dashboardTasks == nil {
+			dashboardTasks = asList(asMap(dashboard)["tasks"])
+		}
+		seen := map[int]bool{}
+		for _, item := range append(dashboardTasks, asList(overdue)...) {
+			task := asMap(item)
+			if !seen[asInt(task["id"])] && (task["is_active"] == nil || asInt(task["is_active"]) == 1) {
+				seen[asInt(task["id"])] = true
+				openTasks = append(openTasks, task)
+			}
+		}
+		blockers, blockerWarnings := kc.taskBlockers(ctx, openTasks)
+		warnings = append(warnings, blockerWarnings...)
+		digests = append(digests, buildStandupDigest(userID, userName(userID), events, openTasks, from, now, dueDays, blockers, projects))
+	} else {
+		// Everybody else is seen through project activity and task assignments.
+		projectIDs := []int{projectID}
+		if projectID == 0 {
+			projectIDs = projectIDs[:0]
+			for id := range projects {
+				projectIDs = append(projectIDs, id)
+			}
+			sort.Ints(projectIDs)
+		}
+		eventLists := make([][]map[string]interface{}, len(projectIDs))
+		taskLists := make([][]map[string]interface{}, len(projectIDs))
+		errs := make([]error, len(projectIDs))
+		forEachConcurrent(len(projectIDs), 4, func(i int) {
+			if eventLists[i], errs[i] = kc.fetchProjectEvents(ctx, projectIDs[i]); errs[i] != nil {
+				return
+			}
+			result, err := kc.callKanboardAPI(ctx, "getAllTasks", map[string]int{"project_id": projectIDs[i], "status_id": 1})
+			if err != nil {
+				errs[i] = fmt.Errorf("Failed to get tasks: %v", err)
+				return
+			}
+			for _, item := range asList(result) {
+				taskLists[i] = append(taskLists[i], asMap(item))
+			}
+		})
+		var events, tasks []map[string]interface{}
+		for i := range projectIDs {
+			if errs[i] != nil {
+				return mcp.NewToolResultError(errs[i].Error()), nil
+			}
+			if warning := historyWarning(eventLists[i], from, kc.location(ctx)); warning != "" {
+				warnings = append(warnings, fmt.Sprintf("%s: %s", projects[projectIDs[i]], warning))
+			}
+			events = append(events, eventLists[i]...)
+			tasks = append(tasks, taskLists[i]...)
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return asInt(events[i]["date_creation"]) < asInt(events[j]["date_creation"])
+		})
+
+		var userIDs []int
+		if user != "" {
+			userID, err := kc.resolveUserID(ctx, user)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			userIDs = []int{userID}
+		} else {
+			// The team: project members plus anyone with assigned tasks or recent activity.
+			members := map[int]bool{}
+			result, err := kc.callKanboardAPICached(ctx, "getProjectUsers", map[string]int{"project_id": projectID})
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("project members are unknown, only people with tasks or activity are listed: %v", err))
+			}
+			for id := range asMap(result) {
+				members[asInt(id)] = true
+			}
+			for _, task := range tasks {
+				members[asInt(task["owner_id"])] = true
+			}
+			for _, event := range events {
+				if !time.Unix(int64(asInt(event["date_creation"])), 0).Before(from) {
+					members[asInt(event["creator_id"])] = true
+				}
+			}
+			delete(members, 0)
+			for id := range members {
+				userIDs = append(userIDs, id)
+			}
+			sort.Ints(userIDs)
+		}
+
+		var assigned []map[string]interface{}
+		for _, task := range tasks {
+			for _, userID := range userIDs {
+				if asInt(task["owner_id"]) == userID {
+					assigned = append(assigned, task)
+				}
+			}
+		}
+		blockers, blockerWarnings := kc.taskBlockers(ctx, assigned)
+		warnings = append(warnings, blockerWarnings...)
+		for _, userID := range userIDs {
+			var openTasks []map[string]interface{}
+			for _, task := range assigned {
+				if asInt(task["owner_id"]) == userID {
+					openTasks = append(openTasks, task)
+				}
+			}
+			digests = append(digests, buildStandupDigest(userID, userName(userID), events, openTasks, from, now, dueDays, blockers, projects))
+		}
+	}
+
+	title := "Standup digest"
+	if projectID != 0 {
+		title += ": " + projects[projectID]
+	}
+	if request.GetString("format", "markdown") == "json" {
+		digest := map[string]interface{}{
+			"title":   title,
+			"since":   from.Format(time.RFC3339),
+			"until":   now.Format(time.RFC3339),
+			"digests": digests,
+		}
+		if len(warnings) > 0 {
+			digest["warnings"] = warnings
+		}
+		resultBytes, err := json.MarshalIndent(digest, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(resultBytes)), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\nSince %s\n", title, from.Format("Mon 2006-01-02 15:04"))
+	for _, warning := range warnings {
+		fmt.Fprintf(&sb, "\n> ⚠️ %s\n", warning)
+	}
+	var quiet []string
+	for _, digest := range digests {
+		if digest.empty() {
+			quiet = append(quiet, digest.User)
+			continue
+		}
+		if len(digests) > 1 {
+			fmt.Fprintf(&sb, "\n## %s\n", digest.User)
+		}
+		sections := []struct {
+			name    string
+			entries []standupEntry
+		}{
+			{"✅ Done", digest.Done},
+			{"🚧 In progress", digest.InProgress},
+			{"⛔ Blocked", digest.Blocked},
+			{"⏰ Overdue", digest.Overdue},
+			{"📅 Due soon", digest.DueSoon},
+		}
+		for _, section := range sections {
+			if len(section.entries) == 0 {
+				continue
+			}
+			fmt.Fprintf(&sb, "\n**%s**\n\n", section.name)
+			for _, e := range section.entries {
+				fmt.Fprintf(&sb, "- #%d %s", e.TaskID, e.Title)
+				if e.Project != "" && projectID == 0 {
+					fmt.Fprintf(&sb, " (%s)", e.Project)
+				}
+				if e.Detail != "" {
+					fmt.Fprintf(&sb, " — %s", e.Detail)
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+	if len(quiet) > 0 {
+		if len(digests) == 1 {
+			sb.WriteString("\nNothing to report.\n")
+		} else {
+			fmt.Fprintf(&sb, "\nNothing to report for: %s\n", strings.Join(quiet, ", "))
+		}
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// releaseNoteEntry is a closed task as listed in release notes.
+type releaseNoteEntry struct {
+	TaskID    int                 `json:"task_id"`
+	Title     string              `json:"title"`
+	Category  string              `json:"category,omitempty"`
+	Tags      []string            `json:"tags,omitempty"`
+	Completed string              `json:"completed"`
+	Links     []map[string]string `json:"links,omitempty"`
+}
+
+// releaseNoteSection is a heading of the changelog and the names (categories or tags)
+// whose tasks it lists; "*" collects everything no other section takes.
+type releaseNoteSection struct {
+	Heading string             `json:"heading"`
+	Entries []releaseNoteEntry `json:"entries"`
+	names   []string
+}
+
+// parseReleaseSections parses "Heading: name, name" section definitions.
+func parseReleaseSections(definitions []string) ([]*releaseNoteSection, error) {
+	var sections []*releaseNoteSection
+	for _, definition := range definitions {
+		heading, names, found := strings.Cut(definition, ":")
+		if !found || strings.TrimSpace(heading) == "" {
+			return nil, fmt.Errorf("section '%s' must be in 'Heading: name, name' form", definition)
+		}
+		section := &releaseNoteSection{Heading: strings.TrimSpace(heading), Entries: []releaseNoteEntry{}}
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				section.names = append(section.names, strings.ToLower(name))
+			}
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+func (kc *kanboardClient) releaseNotesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID, err := request.RequireInt("project_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	groupBy := request.GetString("group_by", "category")
+	if groupBy != "category" && groupBy != "tag" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported group_by '%s' (expected category or tag)", groupBy)), nil
+	}
+	sections, err := parseReleaseSections(request.GetStringSlice("sections", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Only tags and metadata of the query_tasks filters are offered here.
+	query, err := parseTaskQuery(request, kc.location(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// Tag and metadata selections (e.g. a "v2.3" tag) cover any date unless a range is given.
+	dated := request.GetString("from", "") != "" || request.GetString("to", "") != "" || (len(query.tags) == 0 && len(query.metadata) == 0)
+	from, to, err := dateRangeArgs(request, kc.location(ctx), 14)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	items, err := kc.queryTasks(ctx, []int{projectID}, "closed", query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var tasks []map[string]interface{}
+	for _, item := range items {
+		task := asMap(item)
+		completed := int64(asInt(task["date_completed"]))
+		if dated && (completed < from.Unix() || completed > to.Unix()) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return asInt(tasks[i]["date_completed"]) < asInt(tasks[j]["date_completed"])
+	})
+
+	includeLinks := request.GetBool("include_links", true)
+	tags := make([][]string, len(tasks))
+	links := make([][]map[string]string, len(tasks))
+	forEachConcurrent(len(tasks), 8, func(i int) {
+		taskID := asInt(tasks[i]["id"])
+		if result, err := kc.callKanboardAPI(ctx, "getTaskTags", map[string]int{"task_id": taskID}); err == nil {
+			for _, tag := range asMap(result) {
+				tags[i] = append(tags[i], asString(tag))
+			}
+			sort.Strings(tags[i])
+		}
+		if !includeLinks {
+			return
+		}
+		if result, err := kc.callKanboardAPI(ctx, "getAllExternalTaskLinks", map[string]int{"task_id": taskID}); err == nil {
+			for _, item := range asList(result) {
+				link := asMap(item)
+				links[i] = append(links[i], map[string]string{"title": asString(link["title"]), "url": asString(link["url"])})
+			}
+		}
+	})
+
+	loc := kc.location(ctx)
+	resolver := kc.newNameResolver(ctx)
+	byName := map[string]*releaseNoteSection{}
+	var catchAll *releaseNoteSection
+	for _, section := range sections {
+		for _, name := range section.names {
+			if name == "*" {
+				catchAll = section
+			} else if byName[name] == nil {
+				byName[name] = section
+			}
+		}
+	}
+	configured := len(sections) > 0
+	selecting := map[string]bool{}
+	for _, tag := range query.tags {
+		selecting[strings.ToLower(tag)] = true
+	}
+	var other *releaseNoteSection
+	for i, task := range tasks {
+		entry := releaseNoteEntry{
+			TaskID:    asInt(task["id"]),
+			Title:     asString(task["title"]),
+			Category:  resolver.categoryName(ctx, projectID, asInt(task["category_id"])),
+			Tags:      tags[i],
+			Completed: formatTimestamp(asInt(task["date_completed"]), loc),
+			Links:     links[i],
+		}
+		var names []string
+		if groupBy == "category" && entry.Category != "" {
+			names = []string{entry.Category}
+		} else if groupBy == "tag" {
+			// The tags that selected the release (e.g. "v2.3") say nothing about the change.
+			for _, tag := range entry.Tags {
+				if !selecting[strings.ToLower(tag)] {
+					names = append(names, tag)
+				}
+			}
+		}
+
+		var section *releaseNoteSection
+		if configured {
+			// The first configured section that takes any of the task's names wins.
+			for _, candidate := range sections {
+				for _, name := range names {
+					if byName[strings.ToLower(name)] == candidate {
+						section = candidate
+						break
+					}
+				}
+				if section != nil {
+					break
+				}
+			}
+			if section == nil {
+				section = catchAll
+			}
+		} else if len(names) > 0 {
+			section = byName[strings.ToLower(names[0])]
+			if section == nil {
+				section = &releaseNoteSection{Heading: names[0], names: []string{strings.ToLower(names[0])}, Entries: []releaseNoteEntry{}}
+				byName[strings.ToLower(names[0])] = section
+				sections = append(sections, section)
+			}
+		}
+		if section == nil {
+			if other == nil {
+				other = &releaseNoteSection{Heading: request.GetString("other_heading", "Other changes"), Entries: []releaseNoteEntry{}}
+			}
+			section = other
+		}
+		section.Entries = append(section.Entries, entry)
+	}
+	if !configured {
+		sort.SliceStable(sections, func(i, j int) bool {
+			return strings.ToLower(sections[i].Heading) < strings.ToLower(sections[j].Heading)
+		})
+	}
+	if other != nil {
+		sections = append(sections, other)
+	}
+	if sections == nil {
+		sections = []*releaseNoteSection{}
+	}
+
+	version := request.GetString("version", "")
+	if request.GetString("format", "markdown") == "json" {
+		report := map[string]interface{}{
+			"project_id": projectID,
+			"version":    version,
+			"tasks":      len(tasks),
+			"sections":   sections,
+		}
+		if dated {
+			report["from"] = from.In(loc).Format(time.RFC3339)
+			report["to"] = to.In(loc).Format(time.RFC3339)
+		}
+		resultBytes, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(resultBytes)), nil
+	}
+
+	var sb strings.Builder
+	heading := version
+	if heading == "" {
+		heading = "Release notes"
+	}
+	fmt.Fprintf(&sb, "## %s (%s)\n", heading, to.In(loc).Format("2006-01-02"))
+	if len(tasks) == 0 {
+		sb.WriteString("\n_No closed tasks match._\n")
+	}
+	for _, section := range sections {
+		if len(section.Entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n### %s\n\n", section.Heading)
+		for _, entry := range section.Entries {
+			fmt.Fprintf(&sb, "- %s (#%d)", entry.Title, entry.TaskID)
+			var refs []string
+			for _, link := range entry.Links {
+				title := link["title"]
+				if title == "" {
+					title = link["url"]
+				}
+				refs = append(refs, fmt.Sprintf("[%s](%s)", strings.ReplaceAll(title, "]", ")"), link["url"]))
+			}
+			if len(refs) > 0 {
+				sb.WriteString(" — " + strings.Join(refs, ", "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// lintRule is a board hygiene check of lint_project with its default severity.
+type lintRule struct {
+	ID       string
+	Severity string
+}
+
+var lintRules = []lintRule{
+	{"invalid_priority", "error"},            // open task with a priority outside the project's priority_start..priority_end
+	{"overdue_in_done", "warning"},           // open task past its due date sitting in the last column
+	{"closed_with_open_subtasks", "warning"}, // task closed within stale_days days with unfinished subtasks
+	{"wip_exceeded", "warning"},              // column holding more open tasks than its task limit
+	{"unassigned", "warning"},                // open task without an assignee
+	{"stale", "info"},                        // open task not modified for stale_days days
+	{"missing_category", "info"},             // open task without a category although the project has categories
+	{"subtasks_done_task_open", "info"},      // open task whose subtasks are all done but that isn't in the last column
+}
+
+var lintSeverities = map[string]int{"info": 0, "warning": 1, "error": 2}
+
+func lintRuleIDs() []string {
+	ids := make([]string, len(lintRules))
+	for i, rule := range lintRules {
+		ids[i] = rule.ID
+	}
+	return ids
+}
+
+// lintViolation is a rule broken by a task or column, with the suggested fix. Only safe
+// fixes (apply != nil) are applied in fix mode.
+type lintViolation struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	TaskID   int    `json:"task_id,omitempty"`
+	ColumnID int    `json:"column_id,omitempty"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Fix      string `json:"suggested_fix"`
+	Safe     bool   `json:"safe_fix"`
+	Fixed    bool   `json:"fixed,omitempty"`
+	FixError string `json:"fix_error,omitempty"`
+	apply    func(ctx context.Context) error
+}
+
+func (kc *kanboardClient) lintProjectHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID, err := request.RequireInt("project_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	severities := map[string]string{}
+	order := map[string]int{}
+	for i, rule := range lintRules {
+		severities[rule.ID] = rule.Severity
+		order[rule.ID] = i
+	}
+	ruleIDs := lintRuleIDs()
+	enabled := map[string]bool{}
+	for _, id := range request.GetStringSlice("rules", ruleIDs) {
+		if _, ok := severities[id]; !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("unknown rule '%s' (available: %s)", id, strings.Join(ruleIDs, ", "))), nil
+		}
+		enabled[id] = true
+	}
+	for _, pair := range request.GetStringSlice("severity", nil) {
+		id, severity, found := strings.Cut(pair, "=")
+		id, severity = strings.TrimSpace(id), strings.ToLower(strings.TrimSpace(severity))
+		if _, ok := severities[id]; !found || !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("severity override '%s' must be in rule=severity form with a known rule", pair)), nil
+		}
+		if _, ok := lintSeverities[severity]; !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("unknown severity '%s' (expected info, warning or error)", severity)), nil
+		}
+		severities[id] = severity
+	}
+	minSeverity, ok := lintSeverities[request.GetString("min_severity", "info")]
+	if !ok {
+		return mcp.NewToolResultError("min_severity must be info, warning or error"), nil
+	}
+	staleDays := request.GetInt("stale_days", 90)
+	fixClosedSubtasks := request.GetBool("fix_closed_subtasks", false)
+	fixOverdueInDone := request.GetBool("fix_overdue_in_done", false)
+
+	project, err := kc.callKanboardAPI(ctx, "getProjectById", map[string]int{"project_id": projectID})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get project: %v", err)), nil
+	}
+	columns, err := kc.callKanboardAPI(ctx, "getColumns", map[string]int{"project_id": projectID})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get columns: %v", err)), nil
+	}
+	tasks, err := kc.projectTasks(ctx, projectID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	lastColumn, lastPosition := 0, -1
+	for _, item := range asList(columns) {
+		column := asMap(item)
+		if position := asInt(column["position"]); position > lastPosition {
+			lastColumn, lastPosition = asInt(column["id"]), position
+		}
+	}
+	hasCategories := false
+	if enabled["missing_category"] {
+		if result, err := kc.callKanboardAPICached(ctx, "getAllCategories", map[string]int{"project_id": projectID}); err == nil {
+			hasCategories = len(asList(result)) > 0
+		}
+	}
+	now := time.Now()
+	staleBefore := now.Add(-time.Duration(staleDays) * 24 * time.Hour).Unix()
+	// Only recently closed tasks are checked for open subtasks: older ones are history.
+	needsSubtasks := func(task map[string]interface{}) bool {
+		if asInt(task["is_active"]) == 1 {
+			return enabled["subtasks_done_task_open"]
+		}
+		closed := int64(asInt(task["date_completed"]))
+		if closed == 0 {
+			closed = int64(asInt(task["date_modification"]))
+		}
+		return enabled["closed_with_open_subtasks"] && closed >= staleBefore
+	}
+	subtasks := make([][]map[string]interface{}, len(tasks))
+	if enabled["closed_with_open_subtasks"] || enabled["subtasks_done_task_open"] {
+		forEachConcurrent(len(tasks), 8, func(i int) {
+			if !needsSubtasks(tasks[i]) {
+				return
+			}
+			result, err := kc.callKanboardAPI(ctx, "getAllSubtasks", map[string]int{"task_id": asInt(tasks[i]["id"])})
+			if err != nil {
+				return
+			}
+			for _, item := range asList(result) {
+				subtasks[i] = append(subtasks[i], asMap(item))
+			}
+		})
+	}
+
+	violations := []*lintViolation{}
+	report := func(v *lintViolation) {
+		v.Severity = severities[v.Rule]
+		if enabled[v.Rule] && lintSeverities[v.Severity] >= minSeverity {
+			v.Safe = v.apply != nil
+			violations = append(violations, v)
+		}
+	}
+	loc := kc.location(ctx)
+	priorityStart, priorityEnd := asInt(asMap(project)["priority_start"]), asInt(asMap(project)["priority_end"])
+	openPerColumn := map[int]int{}
+	for i, task := range tasks {
+		taskID, title := asInt(task["id"]), asString(task["title"])
+		if asInt(task["is_active"]) != 1 {
+			var open []map[string]interface{}
+			for _, subtask := range subtasks[i] {
+				if asInt(subtask["status"]) != 2 {
+					open = append(open, subtask)
+				}
+			}
+			if len(open) > 0 {
+				// The remaining subtasks may have been dropped rather than done, so finishing
+				// them is only applied when asked for.
+				v := &lintViolation{Rule: "closed_with_open_subtasks", TaskID: taskID, Title: title,
+					Message: fmt.Sprintf("task is closed but %d of its %d subtasks aren't done", len(open), len(subtasks[i])),
+					Fix:     "mark the remaining subtasks as done, or reopen the task"}
+				if fixClosedSubtasks {
+					v.apply = func(ctx context.Context) error {
+						for _, subtask := range open {
+							if _, err := kc.callKanboardAPI(ctx, "updateSubtask", map[string]interface{}{"id": asInt(subtask["id"]), "task_id": taskID, "status": 2}); err != nil {
+								return err
+							}
+						}
+						return nil
+					}
+				}
+				report(v)
+			}
+			continue
+		}
+		openPerColumn[asInt(task["column_id"])]++
+
+		if priority := asInt(task["priority"]); priorityEnd > priorityStart && (priority < priorityStart || priority > priorityEnd) {
+			clamped := priorityStart
+			if priority > priorityEnd {
+				clamped = priorityEnd
+			}
+			report(&lintViolation{Rule: "invalid_priority", TaskID: taskID, Title: title,
+				Message: fmt.Sprintf("priority %d is outside %d..%d", priority, priorityStart, priorityEnd),
+				Fix:     fmt.Sprintf("set the priority to %d", clamped),
+				apply: func(ctx context.Context) error {
+					_, err := kc.callKanboardAPI(ctx, "updateTask", map[string]interface{}{"id": taskID, "priority": clamped})
+					return err
+				}})
+		}
+		if due := int64(asInt(task["date_due"])); due > 0 && due < now.Unix() && asInt(task["column_id"]) == lastColumn {
+			// The last column isn't always "Done" (e.g. "Deployed, awaiting sign-off"), so
+			// closing the task is only applied when asked for.
+			v := &lintViolation{Rule: "overdue_in_done", TaskID: taskID, Title: title,
+				Message: fmt.Sprintf("due %s but still open in the last column", time.Unix(due, 0).In(loc).Format("2006-01-02")),
+				Fix:     "close the task"}
+			if fixOverdueInDone {
+				v.apply = func(ctx context.Context) error {
+					_, err := kc.callKanboardAPI(ctx, "closeTask", map[string]int{"task_id": taskID})
+					return err
+				}
+			}
+			report(v)
+		}
+		if asInt(task["owner_id"]) == 0 {
+			report(&lintViolation{Rule: "unassigned", TaskID: taskID, Title: title, Message: "nobody is assigned", Fix: "assign the task with update_task owner_id"})
+		}
+		if modified := int64(asInt(task["date_modification"])); modified > 0 && modified < staleBefore {
+			report(&lintViolation{Rule: "stale", TaskID: taskID, Title: title,
+				Message: fmt.Sprintf("untouched since %s", time.Unix(modified, 0).In(loc).Format("2006-01-02")),
+				Fix:     "review the task: update, reschedule or close it"})
+		}
+		if hasCategories && asInt(task["category_id"]) == 0 {
+			report(&lintViolation{Rule: "missing_category", TaskID: taskID, Title: title, Message: "no category", Fix: "set a category with update_task category_id"})
+		}
+		if len(subtasks[i]) > 0 && asInt(task["column_id"]) != lastColumn {
+			done := true
+			for _, subtask := range subtasks[i] {
+				if asInt(subtask["status"]) != 2 {
+					done = false
+				}
+			}
+			if done {
+				report(&lintViolation{Rule: "subtasks_done_task_open", TaskID: taskID, Title: title,
+					Message: fmt.Sprintf("all %d subtasks are done", len(subtasks[i])),
+					Fix:     "move the task to the last column or close it"})
+			}
+		}
+	}
+	for _, item := range asList(columns) {
+		column := asMap(item)
+		columnID, limit := asInt(column["id"]), asInt(column["task_limit"])
+		if limit > 0 && openPerColumn[columnID] > limit {
+			report(&lintViolation{Rule: "wip_exceeded", ColumnID: columnID, Title: asString(column["title"]),
+				Message: fmt.Sprintf("%d open tasks for a limit of %d", openPerColumn[columnID], limit),
+				Fix:     "finish or move tasks out of the column, or raise its task limit"})
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if lintSeverities[a.Severity] != lintSeverities[b.Severity] {
+			return lintSeverities[a.Severity] > lintSeverities[b.Severity]
+		}
+		if order[a.Rule] != order[b.Rule] {
+			return order[a.Rule] < order[b.Rule]
+		}
+		return a.TaskID < b.TaskID
+	})
+
+	fixed := 0
+	if request.GetBool("fix", false) {
+		for _, v := range violations {
+			if v.apply == nil {
+				continue
+			}
+			if err := v.apply(ctx); err != nil {
+				v.FixError = err.Error()
+				continue
+			}
+			v.Fixed = true
+			fixed++
+		}
+	}
+
+	counts := map[string]int{"error": 0, "warning": 0, "info": 0}
+	for _, v := range violations {
+		counts[v.Severity]++
+	}
+	if request.GetString("format", "markdown") == "json" {
+		resultBytes, err := json.MarshalIndent(map[string]interface{}{
+			"project_id": projectID,
+			"counts":     counts,
+			"fixed":      fixed,
+			"violations": violations,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(resultBytes)), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Lint report: %s\n\n%d error(s), %d warning(s), %d info", asString(asMap(project)["name"]), counts["error"], counts["warning"], counts["info"])
+	if fixed > 0 {
+		fmt.Fprintf(&sb, " · %d fixed", fixed)
+	}
+	sb.WriteString("\n")
+	if len(violations) == 0 {
+		sb.WriteString("\n_The board is clean._\n")
+	}
+	headings := map[string]string{"error": "❌ Errors", "warning": "⚠️ Warnings", "info": "ℹ️ Info"}
+	current := ""
+	for _, v := range violations {
+		if v.Severity != current {
+			current = v.Severity
+			fmt.Fprintf(&sb, "\n## %s\n\n", headings[current])
+		}
+		subject := fmt.Sprintf("#%d %s", v.TaskID, v.Title)
+		if v.TaskID == 0 {
+			subject = fmt.Sprintf("column '%s'", v.Title)
+		}
+		fmt.Fprintf(&sb, "- `%s` %s: %s → %s", v.Rule, subject, v.Message, v.Fix)
+		switch {
+		case v.Fixed:
+			sb.WriteString(" ✅ fixed")
+		case v.FixError != "":
+			fmt.Fprintf(&sb, " (fix failed: %s)", v.FixError)
+		case v.Safe:
+			sb.WriteString(" (safe fix)")
+		}
+		sb.WriteString("\n")
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// duplicateThreshold is the default similarity from which two tasks count as duplicates.
+const duplicateThreshold = 0.75
+
+var (
+	duplicateWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+	duplicateStopwords   = map[string]bool{
+		"a": true, "an": true, "the": true, "to": true, "of": true, "in": true, "on": true, "for": true,
+		"and": true, "or": true, "is": true, "be": true, "with": true, "from": true, "at": true, "by": true,
+	}
+)
+
+// duplicateText is the normalized form of a task used for comparisons.
+type duplicateText struct {
+	title       string
+	titleWords  map[string]bool
+	titleGrams  map[string]int
+	description map[string]bool
+	reference   string
+	links       map[string]bool
+}
+
+func duplicateWords(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range duplicateWordPattern.FindAllString(strings.ToLower(text), -1) {
+		if !duplicateStopwords[word] {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+func newDuplicateText(title, description, reference string, links []string) duplicateText {
+	words := duplicateWordPattern.FindAllString(strings.ToLower(title), -1)
+	normalized := strings.Join(words, " ")
+	grams := map[string]int{}
+	runes := []rune(normalized)
+	for i := 0; i+2 <= len(runes); i++ {
+		grams[string(runes[i:i+2])]++
+	}
+	text := duplicateText{
+		title:       normalized,
+		titleWords:  duplicateWords(title),
+		titleGrams:  grams,
+		description: duplicateWords(description),
+		reference:   strings.ToLower(strings.TrimSpace(reference)),
+		links:       map[string]bool{},
+	}
+	for _, link := range links {
+		text.links[strings.TrimRight(strings.TrimSpace(link), "/")] = true
+	}
+	return text
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// diceBigrams compares the character bigrams of two strings, which tolerates typos and
+// word variants ("login page" vs "log-in pages") better than whole words.
+func diceBigrams(a, b map[string]int) float64 {
+	total, shared := 0, 0
+	for gram, count := range a {
+		total += count
+		if other := b[gram]; other > 0 {
+			shared += min(count, other)
+		}
+	}
+	for _, count := range b {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2 * float64(shared) / float64(total)
+}
+
+// duplicateScore rates how likely two tasks are duplicates (0..1) and says why. A shared
+// external reference or link is conclusive; otherwise titles weigh 70% and descriptions 30%
+// when both tasks have one.
+func duplicateScore(a, b duplicateText) (float64, string) {
+	if a.reference != "" && a.reference == b.reference {
+		return 1, "same reference " + a.reference
+	}
+	for link := range a.links {
+		if b.links[link] {
+			return 1, "same external link " + link
+		}
+	}
+	if a.title != "" && a.title == b.title {
+		return 1, "same title"
+	}
+	title := math.Max(jaccard(a.titleWords, b.titleWords), diceBigrams(a.titleGrams, b.titleGrams))
+	if len(a.description) < 3 || len(b.description) < 3 {
+		return roundTo(title, 2), "similar title"
+	}
+	description := jaccard(a.description, b.description)
+	score := 0.7*title + 0.3*description
+	if description > title {
+		return roundTo(score, 2), "similar description"
+	}
+	return roundTo(score, 2), "similar title"
+}
+
+// similarOpenTasks returns the open tasks of a project that look like duplicates of the
+// given title and description, most similar first.
+func (kc *kanboardClient) similarOpenTasks(ctx context.Context, projectID int, text duplicateText, threshold float64) ([]map[string]interface{}, error) {
+	result, err := kc.callKanboardAPI(ctx, "getAllTasks", map[string]int{"project_id": projectID, "status_id": 1})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get tasks: %v", err)
+	}
+	similar := []map[string]interface{}{}
+	for _, item := range asList(result) {
+		task := asMap(item)
+		score, reason := duplicateScore(text, newDuplicateText(asString(task["title"]), asString(task["description"]), asString(task["reference"]), nil))
+		if score >= threshold {
+			similar = append(similar, map[string]interface{}{"id": asInt(task["id"]), "title": asString(task["title"]), "score": score, "reason": reason})
+		}
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i]["score"].(float64) > similar[j]["score"].(float64)
+	})
+	return similar, nil
+}
+
+func (kc *kanboardClient) findDuplicatesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	threshold := request.GetFloat("threshold", duplicateThreshold)
+	if threshold <= 0 || threshold > 1 {
+		return mcp.NewToolResultError("threshold must be between 0 and 1"), nil
+	}
+	projects, err := kc.visibleProjects(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get projects: %v", err)), nil
+	}
+	var projectIDs []int
+	for _, id := range request.GetIntSlice("project_ids", nil) {
+		projectIDs = append(projectIDs, id)
+	}
+	if len(projectIDs) == 0 {
+		for id := range projects {
+			projectIDs = append(projectIDs, id)
+		}
+	}
+	sort.Ints(projectIDs)
+
+	var tasks []map[string]interface{}
+	for _, projectID := range projectIDs {
+		result, err := kc.callKanboardAPI(ctx, "getAllTasks", map[string]int{"project_id": projectID, "status_id": 1})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get tasks for project %d: %v", projectID, err)), nil
+		}
+		for _, item := range asList(result) {
+			tasks = append(tasks, asMap(item))
+		}
+	}
+
+	links := make([][]string, len(tasks))
+	if request.GetBool("compare_links", true) {
+		forEachConcurrent(len(tasks), 8, func(i int) {
+			result, err := kc.callKanboardAPI(ctx, "getAllExternalTaskLinks", map[string]int{"task_id": asInt(tasks[i]["id"])})
+			if err != nil {
+				return
+			}
+			for _, item := range asList(result) {
+				links[i] = append(links[i], asString(asMap(item)["url"]))
+			}
+		})
+	}
+	texts := make([]duplicateText, len(tasks))
+	for i, task := range tasks {
+		texts[i] = newDuplicateText(asString(task["title"]), asString(task["description"]), asString(task["reference"]), links[i])
+	}
+
+	type duplicatePair struct {
+		A      int     `json:"task_id"`
+		B      int     `json:"other_task_id"`
+		Score  float64 `json:"score"`
+		Reason string  `json:"reason"`
+	}
+	// Pairs above the threshold are merged into clusters (union-find).
+	parent := make([]int, len(tasks))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	var matched []duplicatePair
+	for i := range tasks {
+		for j := i + 1; j < len(tasks); j++ {
+			score, reason := duplicateScore(texts[i], texts[j])
+			if score < threshold {
+				continue
+			}
+			pair := duplicatePair{A: asInt(tasks[i]["id"]), B: asInt(tasks[j]["id"]), Score: score, Reason: reason}
+			matched = append(matched, pair)
+			parent[find(i)] = find(j)
+		}
+	}
+	index := map[int]int{}
+	for i := range tasks {
+		index[asInt(tasks[i]["id"])] = i
+	}
+	pairs := map[int][]duplicatePair{}
+	for _, pair := range matched {
+		root := find(index[pair.A])
+		pairs[root] = append(pairs[root], pair)
+	}
+	members := map[int][]int{}
+	for i := range tasks {
+		if root := find(i); len(pairs[root]) > 0 {
+			members[root] = append(members[root], i)
+		}
+	}
+
+	type clusterTask struct {
+		ID        int    `json:"id"`
+		Title     string `json:"title"`
+		ProjectID int    `json:"project_id"`
+		Project   string `json:"project"`
+	}
+	type duplicateCluster struct {
+		Tasks []clusterTask   `json:"tasks"`
+		Pairs []duplicatePair `json:"pairs"`
+		Score float64         `json:"score"`
+	}
+	clusters := []duplicateCluster{}
+	for root, indices := range members {
+		cluster := duplicateCluster{Pairs: pairs[root]}
+		for _, i := range indices {
+			projectID := asInt(tasks[i]["project_id"])
+			cluster.Tasks = append(cluster.Tasks, clusterTask{ID: asInt(tasks[i]["id"]), Title: asString(tasks[i]["title"]), ProjectID: projectID, Project: projects[projectID]})
+		}
+		sort.Slice(cluster.Tasks, func(a, b int) bool { return cluster.Tasks[a].ID < cluster.Tasks[b].ID })
+		for _, pair := range cluster.Pairs {
+			cluster.Score = math.Max(cluster.Score, pair.Score)
+		}
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(a, b int) bool {
+		if clusters[a].Score != clusters[b].Score {
+			return clusters[a].Score > clusters[b].Score
+		}
+		return clusters[a].Tasks[0].ID < clusters[b].Tasks[0].ID
+	})
+
+	if request.GetString("format", "markdown") == "json" {
+		resultBytes, err := json.MarshalIndent(map[string]interface{}{
+			"threshold":     threshold,
+			"tasks_checked": len(tasks),
+			"clusters":      clusters,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal API result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(resultBytes)), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Likely duplicates\n\n%d open task(s) checked, %d cluster(s) at similarity ≥ %g\n", len(tasks), len(clusters), threshold)
+	for n, cluster := range clusters {
+		fmt.Fprintf(&sb, "\n## Cluster %d (similarity %g)\n\n", n+1, cluster.Score)
+		for _, task := range cluster.Tasks {
+			fmt.Fprintf(&sb, "- #%d %s", task.ID, task.Title)
+			if len(projectIDs) > 1 {
+				fmt.Fprintf(&sb, " (%s)", task.Project)
+			}
+			sb.WriteString("\n")
+		}
+		for _, pair := range cluster.Pairs {
+			fmt.Fprintf(&sb, "  - #%d ↔ #%d: %g, %s\n", pair.A, pair.B, pair.Score, pair.Reason)
+		}
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, offset := range []int{0, 1, 25, 1000} {
+		decoded, err := decodeCursor(encodeCursor(offset))
+		if err != nil {
+			t.Fatalf("decodeCursor(encodeCursor(%d)): %v", offset, err)
+		}
+		if decoded != offset {
+			t.Errorf("decodeCursor(encodeCursor(%d)) = %d", offset, decoded)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "%%%"},
+		{"missing prefix", "MTA"},           // "10"
+		{"negative offset", "b2Zmc2V0Oi0x"}, // "offset:-1"
+		{"not a number", "b2Zmc2V0OmFi"},    // "offset:ab"
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if offset, err := decodeCursor(tt.cursor); err == nil {
+				t.Errorf("decodeCursor(%q) = %d, want an error", tt.cursor, offset)
+			}
+		})
+	}
+}
+
+func TestFormatPagedListResult(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{"id": "1", "title": "b", "priority": "2"},
+		map[string]interface{}{"id": "2", "title": "a", "priority": "3"},
+		map[string]interface{}{"id": "3", "title": "d", "priority": "1"},
+		map[string]interface{}{"id": "4", "title": "c", "priority": "0"},
+		map[string]interface{}{"id": "5", "title": "e", "priority": "2"},
+	}
+	tests := []struct {
+		name       string
+		args       map[string]interface{}
+		wantIDs    []string
+		wantOffset int
+		wantCursor string
+	}{
+		{"first page", map[string]interface{}{"limit": 2}, []string{"1", "2"}, 0, encodeCursor(2)},
+		{"cursor", map[string]interface{}{"limit": 2, "cursor": encodeCursor(2)}, []string{"3", "4"}, 2, encodeCursor(4)},
+		{"last page has no cursor", map[string]interface{}{"limit": 2, "offset": 4}, []string{"5"}, 4, ""},
+		{"offset past the end", map[string]interface{}{"limit": 2, "offset": 10}, []string{}, 10, ""},
+		{"no limit", map[string]interface{}{"offset": 3}, []string{"4", "5"}, 3, ""},
+		{"sorted", map[string]interface{}{"limit": 3, "sort_by": "title"}, []string{"2", "1", "4"}, 0, encodeCursor(3)},
+		{"sorted desc", map[string]interface{}{"limit": 2, "sort_by": "priority"}, []string{"2", "1"}, 0, encodeCursor(2)},
+	}
+	kc := newKanboardClient("http://127.0.0.1:0/jsonrpc.php", "key", "", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tt.args
+			list := make([]interface{}, len(items))
+			copy(list, items)
+			result, err := kc.formatPagedListResult(context.Background(), request, list, "task")
+			if err != nil || result.IsError {
+				t.Fatalf("formatPagedListResult: %v %v", err, result.Content)
+			}
+			var page struct {
+				Total      int                      `json:"total"`
+				Offset     int                      `json:"offset"`
+				Count      int                      `json:"count"`
+				NextCursor string                   `json:"next_cursor"`
+				Items      []map[string]interface{} `json:"items"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page); err != nil {
+				t.Fatalf("invalid envelope: %v", err)
+			}
+			ids := []string{}
+			for _, item := range page.Items {
+				ids = append(ids, asString(item["id"]))
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("items = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("items = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+			if page.Total != len(items) || page.Offset != tt.wantOffset || page.Count != len(tt.wantIDs) {
+				t.Errorf("total/offset/count = %d/%d/%d, want %d/%d/%d", page.Total, page.Offset, page.Count, len(items), tt.wantOffset, len(tt.wantIDs))
+			}
+			if page.NextCursor != tt.wantCursor {
+				t.Errorf("next_cursor = %q, want %q", page.NextCursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestFormatPagedListResultRejectsBadArguments(t *testing.T) {
+	kc := newKanboardClient("http://127.0.0.1:0/jsonrpc.php", "key", "", "")
+	for _, args := range []map[string]interface{}{
+		{"cursor": "garbage!"},
+		{"limit": -1},
+		{"sort_by": "color"},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := kc.formatPagedListResult(context.Background(), request, []interface{}{}, "task")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.IsError {
+			t.Errorf("arguments %v: want an error result", args)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseTaskQuery(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	intp := func(v int) *int { return &v }
+	boolp := func(v bool) *bool { return &v }
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want taskQuery
+	}{
+		{"no filters", map[string]interface{}{}, taskQuery{}},
+		{
+			name: "names and text",
+			args: map[string]interface{}{"assignee": "bob", "column": "Done", "swimlane": "Ops", "category": "Bug", "text": "Login Page"},
+			want: taskQuery{assignee: "bob", column: "Done", swimlane: "Ops", category: "Bug", text: "login page"},
+		},
+		{
+			name: "tags",
+			args: map[string]interface{}{"tags": []interface{}{"api", "urgent"}, "tags_match": "all"},
+			want: taskQuery{tags: []string{"api", "urgent"}, allTags: true},
+		},
+		{
+			name: "ranges keep explicit zeros",
+			args: map[string]interface{}{"priority_min": 0, "priority_max": 2, "score_max": 0},
+			want: taskQuery{priorityMin: intp(0), priorityMax: intp(2), scoreMax: intp(0)},
+		},
+		{
+			name: "has_open_subtasks false",
+			args: map[string]interface{}{"has_open_subtasks": false},
+			want: taskQuery{hasOpenSubtasks: boolp(false)},
+		},
+		{
+			name: "dates in the Kanboard timezone",
+			args: map[string]interface{}{"due_before": "2024-06-01", "due_after": "05/01/2024", "modified_since": "2024-05-20 08:00"},
+			want: taskQuery{
+				dueBefore:     time.Date(2024, 6, 1, 0, 0, 0, 0, loc),
+				dueAfter:      time.Date(2024, 5, 1, 0, 0, 0, 0, loc),
+				modifiedSince: time.Date(2024, 5, 20, 8, 0, 0, 0, loc),
+			},
+		},
+		{
+			name: "metadata",
+			args: map[string]interface{}{"metadata": []interface{}{"sprint = 4", "team=web=core"}},
+			want: taskQuery{metadata: map[string]string{"sprint": "4", "team": "web=core"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tt.args
+			got, err := parseTaskQuery(request, loc)
+			if err != nil {
+				t.Fatalf("parseTaskQuery(%v): %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("parseTaskQuery(%v)\n got %+v\nwant %+v", tt.args, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTaskQueryErrors(t *testing.T) {
+	for _, args := range []map[string]interface{}{
+		{"due_before": "someday"},
+		{"modified_since": "2024-13-01"},
+		{"metadata": []interface{}{"sprint"}},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		if _, err := parseTaskQuery(request, time.UTC); err == nil {
+			t.Errorf("parseTaskQuery(%v): want an error", args)
+		}
+	}
+}
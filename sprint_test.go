@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSprintMembers(t *testing.T) {
+	sprint := sprintInfo{
+		ID:    7,
+		Start: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 5, 14, 23, 59, 59, 0, time.UTC),
+	}
+	dueInSprint := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC).Unix()
+	tasks := []map[string]interface{}{
+		{"id": "1", "date_due": dueInSprint},
+		{"id": "2", "date_due": dueInSprint},
+		{"id": "3", "date_due": "0"},
+		{"id": "4", "date_due": dueInSprint},
+	}
+	ids := func(members []map[string]interface{}) []int {
+		result := []int{}
+		for _, task := range members {
+			result = append(result, asInt(task["id"]))
+		}
+		return result
+	}
+	tests := []struct {
+		name        string
+		assignments map[int]int
+		want        []int
+	}{
+		{"tagged tasks", map[int]int{1: 7, 3: 7, 4: 8}, []int{1, 3}},
+		{"due dates never make members", map[int]int{}, []int{}},
+		{"removing the last task empties the sprint", map[int]int{1: 0, 4: 8}, []int{}},
+		{"tasks of other sprints", map[int]int{1: 6, 2: 8}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(sprintMembers(sprint, tasks, tt.assignments)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sprintMembers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
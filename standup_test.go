@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildStandupDigest(t *testing.T) {
+	now := time.Date(2024, 5, 15, 9, 0, 0, 0, time.UTC)
+	from := previousWorkday(now)
+	at := func(hours int) int64 { return from.Add(time.Duration(hours) * time.Hour).Unix() }
+	events := []map[string]interface{}{
+		{"event_name": "task.move.column", "creator_id": "1", "task_id": "1", "date_creation": at(-5), "task": map[string]interface{}{"id": "1", "title": "Old news"}},
+		{"event_name": "task.close", "creator_id": "1", "task_id": "2", "date_creation": at(2), "task": map[string]interface{}{"id": "2", "title": "Ship", "project_id": "1"}},
+		{"event_name": "comment.create", "creator_id": "1", "task_id": "3", "date_creation": at(3)},
+		{"event_name": "task.move.column", "creator_id": "1", "task_id": "3", "date_creation": at(4), "task": map[string]interface{}{"id": "3", "column_title": "Review"}},
+		{"event_name": "comment.create", "creator_id": "2", "task_id": "4", "date_creation": at(5)},
+		{"event_name": "subtask.update", "creator_id": "1", "task_id": "6", "date_creation": at(6), "subtask": map[string]interface{}{"title": "Tests", "status": "2"}, "task": map[string]interface{}{"id": "6", "title": "Bob's task", "project_id": "1"}},
+	}
+	openTasks := []map[string]interface{}{
+		{"id": "3", "title": "Review API", "project_id": "1"},
+		{"id": "4", "title": "Blocked one", "project_id": "1", "date_due": now.AddDate(0, 0, 1).Unix()},
+		{"id": "5", "title": "Late", "project_id": "1", "date_due": now.AddDate(0, 0, -2).Unix(), "date_started": now.AddDate(0, 0, -5).Unix()},
+	}
+	blockers := map[int][]int{4: {7, 8}}
+	projects := map[int]string{1: "Web"}
+
+	digest := buildStandupDigest(1, "Alice", events, openTasks, from, now, 3, blockers, projects)
+	want := standupDigest{
+		UserID: 1,
+		User:   "Alice",
+		Done: []standupEntry{
+			{TaskID: 2, Title: "Ship", Project: "Web", Detail: "closed"},
+			{TaskID: 6, Title: "Bob's task", Project: "Web", Detail: "finished subtask Tests"},
+		},
+		InProgress: []standupEntry{
+			{TaskID: 3, Title: "Review API", Project: "Web", Detail: "commented, moved to Review"},
+			{TaskID: 5, Title: "Late", Project: "Web", Detail: "started 2024-05-10"},
+		},
+		Blocked: []standupEntry{{TaskID: 4, Title: "Blocked one", Project: "Web", Detail: "blocked by #7, #8"}},
+		Overdue: []standupEntry{{TaskID: 5, Title: "Late", Project: "Web", Detail: "due 2024-05-13 (2 days ago)"}},
+		DueSoon: []standupEntry{{TaskID: 4, Title: "Blocked one", Project: "Web", Detail: "due 2024-05-16 09:00"}},
+	}
+	if !reflect.DeepEqual(digest, want) {
+		t.Errorf("buildStandupDigest\n got %+v\nwant %+v", digest, want)
+	}
+
+	if quiet := buildStandupDigest(9, "Carol", events, nil, from, now, 3, nil, projects); !quiet.empty() {
+		t.Errorf("digest of a user without activity or tasks = %+v, want empty", quiet)
+	}
+}
+
+func TestPreviousWorkday(t *testing.T) {
+	monday := time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC)
+	if got, want := previousWorkday(monday), time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("previousWorkday(Monday) = %v, want %v", got, want)
+	}
+	if got, want := previousWorkday(monday.AddDate(0, 0, 2)), time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("previousWorkday(Wednesday) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrackedTime(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC) }
+	task := map[string]interface{}{"id": "10", "project_id": "1", "title": "Ship", "owner_id": "1", "time_spent": "4"}
+	subtask := map[string]interface{}{"id": "20", "user_id": "2", "time_spent": "3"}
+	subtaskEvent := func(at time.Time, spent float64) map[string]interface{} {
+		return map[string]interface{}{"task_id": "10", "date_creation": at.Unix(), "subtask": map[string]interface{}{"id": "20", "user_id": "2", "time_spent": spent}}
+	}
+	entry := func(at time.Time, userID int, spent float64) timeEntry {
+		return timeEntry{At: at, UserID: userID, ProjectID: 1, TaskID: 10, TaskTitle: "Ship", Spent: spent}
+	}
+	tests := []struct {
+		name     string
+		subtasks []map[string]interface{}
+		tracked  map[int]map[int]float64
+		events   []map[string]interface{}
+		want     []timeEntry
+	}{
+		{
+			name:     "events date part of the tracked time",
+			subtasks: []map[string]interface{}{subtask},
+			tracked:  map[int]map[int]float64{20: {2: 3}},
+			events:   []map[string]interface{}{subtaskEvent(day(10), 1), subtaskEvent(day(12), 2.5)},
+			want:     []timeEntry{entry(day(10), 2, 1), entry(day(12), 2, 1.5), entry(time.Time{}, 2, 0.5)},
+		},
+		{
+			name:     "snapshots never date more than was tracked",
+			subtasks: []map[string]interface{}{subtask},
+			tracked:  map[int]map[int]float64{20: {2: 1}},
+			events:   []map[string]interface{}{subtaskEvent(day(10), 3)},
+			want:     []timeEntry{entry(day(10), 2, 1)},
+		},
+		{
+			name:     "time tracked by several users",
+			subtasks: []map[string]interface{}{subtask},
+			tracked:  map[int]map[int]float64{20: {2: 1, 5: 2}},
+			want:     []timeEntry{entry(time.Time{}, 2, 1), entry(time.Time{}, 5, 2)},
+		},
+		{
+			name:     "manual time without timers",
+			subtasks: []map[string]interface{}{subtask},
+			tracked:  map[int]map[int]float64{20: {2: 0}},
+			want:     []timeEntry{entry(time.Time{}, 2, 3)},
+		},
+		{
+			name:   "task without subtasks",
+			events: []map[string]interface{}{{"task_id": "10", "date_creation": day(5).Unix(), "task": map[string]interface{}{"time_spent": "4"}}},
+			want:   []timeEntry{entry(day(5), 1, 4)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := trackedTime(task, tt.subtasks, tt.tracked, tt.events)
+			for i := range got {
+				if !got[i].At.IsZero() {
+					got[i].At = got[i].At.UTC()
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trackedTime\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
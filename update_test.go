@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPartialUpdate(t *testing.T) {
+	parseDate := func(value string) (string, error) { return value + " 00:00", nil }
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{"omitted fields are untouched", map[string]interface{}{}, map[string]interface{}{"id": 1}, false},
+		{
+			name: "zero values are sent",
+			args: map[string]interface{}{"priority": 0, "score": 0.0, "title": "", "active": false, "tags": []interface{}{}},
+			want: map[string]interface{}{"id": 1, "priority": 0, "score": 0.0, "title": "", "is_active": false, "tags": []string{}},
+		},
+		{
+			name: "values are sent",
+			args: map[string]interface{}{"priority": 2, "score": 1.5, "title": "Ship", "active": true, "tags": []interface{}{"api"}, "due": "2024-05-01"},
+			want: map[string]interface{}{"id": 1, "priority": 2, "score": 1.5, "title": "Ship", "is_active": true, "tags": []string{"api"}, "date_due": "2024-05-01 00:00"},
+		},
+		{
+			name: "null clears",
+			args: map[string]interface{}{"priority": nil, "title": nil, "tags": nil, "due": nil},
+			want: map[string]interface{}{"id": 1, "priority": 0, "title": "", "tags": []string{}, "date_due": ""},
+		},
+		{
+			name: "clear wins over a value",
+			args: map[string]interface{}{"score": 3, "active": true, "clear": []interface{}{"score", "active", "due"}},
+			want: map[string]interface{}{"id": 1, "score": 0, "is_active": false, "date_due": ""},
+		},
+		{"empty date clears", map[string]interface{}{"due": " "}, map[string]interface{}{"id": 1, "date_due": ""}, false},
+		{"unknown cleared field", map[string]interface{}{"clear": []interface{}{"owner"}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tt.args
+			update := newPartialUpdate(request, map[string]interface{}{"id": 1})
+			update.intField("priority", "priority")
+			update.floatField("score", "score")
+			update.stringField("title", "title")
+			update.boolField("active", "is_active")
+			update.stringsField("tags", "tags")
+			if err := update.dateField("due", "date_due", parseDate); err != nil {
+				t.Fatal(err)
+			}
+			err := update.unknownCleared()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("unknownCleared: want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(update.params, tt.want) {
+				t.Errorf("params\n got %#v\nwant %#v", update.params, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithClear(t *testing.T) {
+	tool := mcp.NewTool("update", withClear("description", "date_due"))
+	clear, ok := tool.InputSchema.Properties["clear"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("clear argument missing: %#v", tool.InputSchema.Properties)
+	}
+	if clear["type"] != "array" {
+		t.Errorf("clear type = %v, want array", clear["type"])
+	}
+	items, _ := clear["items"].(map[string]interface{})
+	if !reflect.DeepEqual(items["enum"], []string{"description", "date_due"}) {
+		t.Errorf("clear items = %#v, want the field names", items)
+	}
+}